@@ -0,0 +1,61 @@
+package main
+
+import "sync/atomic"
+
+// OverlayAction 托盘菜单、全局热键等请求的操作，由 Game.Update 在游戏线程中执行
+type OverlayAction int
+
+const (
+	ActionToggleSpotlight OverlayAction = iota // 开关演示聚光灯
+	ActionUndoInk                              // 撤销最后一条批注
+	ActionClearInk                             // 清除所有批注
+	ActionToggleLaser                          // 开关激光笔模式
+	ActionPause                                // 暂停 / 恢复覆盖层
+	ActionToggleRipples                        // 开关点击波纹
+	ActionCyclePreset                          // 切换到下一个轨迹图层预设
+	ActionOpenConfig                           // 打开设置窗口
+)
+
+// overlayPaused 覆盖层是否已暂停，托盘菜单需要在其他线程读取
+// 暂停时不绘制任何效果，但仍然处理热键，以便恢复
+var overlayPaused atomic.Bool
+
+// 托盘菜单中显示的开关状态，由游戏线程发布，托盘线程只读取这些值而不访问 Config
+var (
+	traySpotlight atomic.Bool
+	trayLaser     atomic.Bool
+	trayInk       atomic.Bool
+)
+
+// publishTrayState 发布托盘菜单需要的开关状态
+func publishTrayState(c *Config) {
+	traySpotlight.Store(c.Spotlight.Enabled)
+	trayLaser.Store(c.Laser.Enabled)
+	trayInk.Store(c.Ink.Enabled)
+}
+
+// applyAction 执行 a
+func (g *Game) applyAction(a OverlayAction) {
+	switch a {
+	case ActionToggleSpotlight:
+		g.config.Spotlight.Enabled = !g.config.Spotlight.Enabled
+	case ActionUndoInk:
+		g.traceManager.UndoInk()
+	case ActionClearInk:
+		g.traceManager.ClearInk()
+	case ActionToggleLaser:
+		g.config.Laser.Enabled = !g.config.Laser.Enabled
+	case ActionPause:
+		overlayPaused.Store(!overlayPaused.Load())
+	case ActionToggleRipples:
+		g.config.IsRipple = !g.config.IsRipple
+	case ActionCyclePreset:
+		g.config.Layers, g.customLayers = nextTrailLayerPreset(g.config.Layers, g.customLayers)
+	case ActionOpenConfig:
+		// 设置窗口已经打开时忽略
+		select {
+		case g.openConfigChan <- g.config.Clone():
+		default:
+		}
+	}
+}
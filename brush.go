@@ -0,0 +1,208 @@
+package main
+
+import (
+	"image"
+	"image/draw"
+	_ "image/png"
+	"log"
+	"math"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 纹理笔刷模式
+const (
+	BrushStretch = "stretch" // 整个纹理从尾端到头部拉伸在轨迹上
+	BrushTile    = "tile"    // 按纹理宽高比沿轨迹重复，从头部开始排列
+	BrushStamp   = "stamp"   // 沿轨迹按间距盖章，纹理随轨迹方向旋转
+)
+
+// BrushConfig 纹理笔刷设置
+// 纹理的横向对应轨迹方向 (左 = 尾端，右 = 头部)，纵向对应轨迹宽度
+type BrushConfig struct {
+	Path    string  `json:"path"`    // PNG 纹理路径，为空时使用纯色轨迹
+	Mode    string  `json:"mode"`    // "stretch", "tile", "stamp"
+	Spacing float64 `json:"spacing"` // stamp 模式下相邻印章的间距 (像素)
+	Tint    bool    `json:"tint"`    // 用轨迹颜色给纹理着色，关闭时保留纹理原色
+}
+
+func defaultBrushConfig() BrushConfig {
+	return BrushConfig{
+		Mode:    BrushStretch,
+		Spacing: 12,
+	}
+}
+
+// brushTexture 按路径加载的笔刷纹理
+type brushTexture struct {
+	path string
+	img  *image.RGBA   // 软件渲染路径使用
+	eimg *ebiten.Image // 延迟上传，保证纯软件渲染路径不需要 GPU
+}
+
+// load 保证纹理与 path 一致，返回 false 表示未设置或加载失败
+// 加载失败时记录日志，路径改变前不再重试
+func (b *brushTexture) load(path string) bool {
+	if path == b.path {
+		return b.img != nil
+	}
+	b.release()
+	b.path = path
+	if path == "" {
+		return false
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		log.Printf("Failed to open brush texture %s: %v", path, err)
+		return false
+	}
+	defer f.Close()
+
+	src, _, err := image.Decode(f)
+	if err != nil {
+		log.Printf("Failed to decode brush texture %s: %v", path, err)
+		return false
+	}
+	bounds := src.Bounds()
+	if bounds.Empty() {
+		return false
+	}
+	b.img = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(b.img, b.img.Bounds(), src, bounds.Min, draw.Src)
+	return true
+}
+
+// image 返回 GPU 纹理
+func (b *brushTexture) image() *ebiten.Image {
+	if b.eimg == nil && b.img != nil {
+		b.eimg = ebiten.NewImageFromImage(b.img)
+	}
+	return b.eimg
+}
+
+func (b *brushTexture) release() {
+	if b.eimg != nil {
+		b.eimg.Deallocate()
+	}
+	b.img, b.eimg = nil, nil
+}
+
+// brushColor 笔刷顶点的颜色：着色时使用轨迹颜色，否则为只带透明度的白色
+func brushColor(c vertexColor, tint bool) vertexColor {
+	if tint {
+		return c
+	}
+	return vertexColor{c.A, c.A, c.A, c.A}
+}
+
+// buildBrushTrail 生成纹理笔刷轨迹的三角形，points 从尾端排列到头部
+// widths 为每个点的半宽，arc 为每个点到头部的弧长比例，width 为图层的基础宽度
+func (tm *TraceManager) buildBrushTrail(points []TracePoint, widths, arc []float64, width float64) {
+	cfg := &tm.config.Brush
+	tex := tm.brush.img.Bounds().Size()
+	texW, texH := float64(tex.X), float64(tex.Y)
+
+	if cfg.Mode == BrushStamp {
+		tm.buildBrushStamps(points, widths, texW, texH)
+		return
+	}
+
+	// 每个点到头部的距离，tile 模式按距离计算纹理坐标
+	total := 0.0
+	for i := 1; i < len(points); i++ {
+		total += math.Hypot(points[i].X-points[i-1].X, points[i].Y-points[i-1].Y)
+	}
+	srcX := func(i int) float64 {
+		if cfg.Mode == BrushTile {
+			// 一个纹理的长度与轨迹宽度保持纹理的宽高比
+			tileLen := texW / texH * 2 * width
+			return texW - arc[i]*total/tileLen*texW
+		}
+		return (1 - arc[i]) * texW
+	}
+
+	for i := 0; i < len(points)-1; i++ {
+		p1, p2 := &points[i], &points[i+1]
+		dx, dy := p2.X-p1.X, p2.Y-p1.Y
+		l := math.Hypot(dx, dy)
+		if l == 0 {
+			continue
+		}
+		nx, ny := -dy/l, dx/l
+		w1, w2 := widths[i], widths[i+1]
+		c1 := brushColor(tm.colors[i], cfg.Tint)
+		c2 := brushColor(tm.colors[i+1], cfg.Tint)
+		u1, u2 := float32(srcX(i)), float32(srcX(i+1))
+
+		v1 := trailVertex(p1.X+nx*w1, p1.Y+ny*w1, p1, arc[i], -1, c1)
+		v2 := trailVertex(p1.X-nx*w1, p1.Y-ny*w1, p1, arc[i], 1, c1)
+		v3 := trailVertex(p2.X+nx*w2, p2.Y+ny*w2, p2, arc[i+1], -1, c2)
+		v4 := trailVertex(p2.X-nx*w2, p2.Y-ny*w2, p2, arc[i+1], 1, c2)
+		// 左侧为纹理顶边，右侧为纹理底边
+		v1.SrcX, v1.SrcY = u1, 0
+		v2.SrcX, v2.SrcY = u1, float32(texH)
+		v3.SrcX, v3.SrcY = u2, 0
+		v4.SrcX, v4.SrcY = u2, float32(texH)
+
+		idx := uint32(len(tm.vertices))
+		tm.vertices = append(tm.vertices, v1, v2, v3, v4)
+		tm.indices = append(tm.indices, idx, idx+1, idx+2, idx+1, idx+3, idx+2)
+	}
+}
+
+// buildBrushStamps 从头部开始沿轨迹每隔 Spacing 像素放置一个纹理
+// 纹理高度为轨迹宽度，宽度按纹理的宽高比缩放
+func (tm *TraceManager) buildBrushStamps(points []TracePoint, widths []float64, texW, texH float64) {
+	cfg := &tm.config.Brush
+	spacing := math.Max(1, cfg.Spacing)
+
+	next := 0.0 // 到下一个印章还需要走过的距离
+	for i := len(points) - 1; i > 0; i-- {
+		p1, p2 := &points[i], &points[i-1]
+		dx, dy := p2.X-p1.X, p2.Y-p1.Y
+		l := math.Hypot(dx, dy)
+		if l == 0 {
+			continue
+		}
+		// 纹理朝向运动方向 (从 p2 指向 p1)
+		angle := math.Atan2(-dy, -dx)
+		for ; next <= l; next += spacing {
+			t := next / l
+			x, y := p1.X+dx*t, p1.Y+dy*t
+			half := lerp(widths[i], widths[i-1], t)
+			if half < 0.5 {
+				continue
+			}
+			c := brushColor(tm.colors[i], cfg.Tint)
+			p := p1
+			if t > 0.5 {
+				c = brushColor(tm.colors[i-1], cfg.Tint)
+				p = p2
+			}
+			tm.addStamp(x, y, half*texW/texH, half, angle, texW, texH, p, c)
+		}
+		next -= l
+	}
+}
+
+// addStamp 添加一个中心在 (x, y)、半宽 hw、半高 hh、旋转 angle 的纹理四边形
+func (tm *TraceManager) addStamp(x, y, hw, hh, angle, texW, texH float64, p *TracePoint, c vertexColor) {
+	sin, cos := math.Sincos(angle)
+	corner := func(sx, sy, u, v float64) ebiten.Vertex {
+		vx := x + sx*hw*cos - sy*hh*sin
+		vy := y + sx*hw*sin + sy*hh*cos
+		vert := trailVertex(vx, vy, p, 0, sy, c)
+		vert.SrcX, vert.SrcY = float32(u), float32(v)
+		return vert
+	}
+	idx := uint32(len(tm.vertices))
+	tm.vertices = append(tm.vertices,
+		corner(-1, -1, 0, 0),
+		corner(1, -1, texW, 0),
+		corner(-1, 1, 0, texH),
+		corner(1, 1, texW, texH),
+	)
+	tm.indices = append(tm.indices, idx, idx+1, idx+2, idx+1, idx+3, idx+2)
+}
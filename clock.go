@@ -0,0 +1,57 @@
+package main
+
+import (
+	"time"
+)
+
+// Clock 时间源
+// 所有动画 (轨迹寿命、波纹扩散、彩虹循环) 都以秒为单位按它计算，
+// 与 TPS 无关；测试时可以注入 ManualClock 或 ScriptedInput 获得确定的结果
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock 使用系统时间
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// ManualClock 手动推进的时钟
+type ManualClock struct {
+	t time.Time
+}
+
+// NewManualClock 创建从 start 开始的手动时钟
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{t: start}
+}
+
+// Now 实现 Clock
+func (c *ManualClock) Now() time.Time {
+	return c.t
+}
+
+// Advance 把时钟向前推进 d
+func (c *ManualClock) Advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+// elapsedSeconds 返回 prev 到 now 经过的秒数
+// prev 为零值 (首次调用) 或时钟回拨时返回 0
+func elapsedSeconds(prev, now time.Time) float64 {
+	if prev.IsZero() || now.Before(prev) {
+		return 0
+	}
+	return now.Sub(prev).Seconds()
+}
+
+// lifeAt 返回存活 lifetime 秒的效果在 age 秒时的生命值 (1 -> 0)
+// lifetime 不大于 0 时立即消失
+func lifeAt(age, lifetime float64) float64 {
+	if lifetime <= 0 {
+		return 0
+	}
+	return 1 - age/lifetime
+}
@@ -4,35 +4,94 @@ import (
 	"encoding/json"
 	"image/color"
 	"os"
+	"reflect"
+	"slices"
 )
 
 // Config 存储应用程序配置
 type Config struct {
-	TailColor         [4]uint8 `json:"tail_color"`          // 轨迹颜色 RGBA
-	TailLength        int      `json:"tail_length"`         // 轨迹最大点数
-	TailWidth         float64  `json:"tail_width"`          // 轨迹头部宽度
-	DecaySpeed        float64  `json:"decay_speed"`         // 衰减速度 (每帧减少的透明度/宽度比例)
-	IsRainbow         bool     `json:"is_rainbow"`          // 是否开启彩虹模式
-	IsRipple          bool     `json:"is_ripple"`           // 是否开启点击波纹
-	RippleGrowthSpeed float64  `json:"ripple_growth_speed"` // 波纹扩散速度
-	RippleDecaySpeed  float64  `json:"ripple_decay_speed"`  // 波纹消失速度
-	RippleWidth       float64  `json:"ripple_width"`        // 波纹圆环宽度
-	Language          string   `json:"language"`            // 语言: "auto", "en", "zh"
+	TailColor        [4]uint8        `json:"tail_color"`        // 轨迹颜色 RGBA
+	TailLength       int             `json:"tail_length"`       // 轨迹最大点数
+	TailWidth        float64         `json:"tail_width"`        // 轨迹头部宽度
+	TailLifetime     float64         `json:"tail_lifetime"`     // 轨迹点存活时间 (秒)
+	Smoothing        string          `json:"smoothing"`         // 轨迹平滑: "none", "catmull_rom", "centripetal"
+	SmoothingTension float64         `json:"smoothing_tension"` // 平滑张力 0-1，越大越接近折线
+	WidthProfile     Profile         `json:"width_profile"`     // 宽度沿轨迹的变化曲线
+	AlphaProfile     Profile         `json:"alpha_profile"`     // 透明度沿轨迹的变化曲线
+	TailGradient     []GradientStop  `json:"tail_gradient"`     // 沿轨迹的渐变色标，为空时使用 TailColor
+	SpeedStyle       SpeedStyle      `json:"speed_style"`       // 速度到宽度、颜色、透明度、采样密度的映射
+	IsRainbow        bool            `json:"is_rainbow"`        // 是否开启彩虹模式
+	RainbowMode      string          `json:"rainbow_mode"`      // 彩虹模式: "uniform", "trail", "speed"
+	RainbowSpace     string          `json:"rainbow_space"`     // 彩虹色彩空间: "oklch", "hsv"
+	RainbowPeriod    float64         `json:"rainbow_period"`    // 色相循环一周的时间 (秒)
+	RainbowSpread    float64         `json:"rainbow_spread"`    // trail 模式下从头到尾跨越的色相比例 (1 = 整个色环)
+	IsRipple         bool            `json:"is_ripple"`         // 是否开启点击波纹
+	RippleGrowth     float64         `json:"ripple_growth"`     // 波纹扩散速度 (像素/秒)
+	RippleDuration   float64         `json:"ripple_duration"`   // 波纹持续时间 (秒)
+	RippleWidth      float64         `json:"ripple_width"`      // 波纹圆环宽度
+	ButtonRipples    ButtonRipples   `json:"button_ripples"`    // 各鼠标按键的波纹样式
+	ModifierRipples  ModifierStyle   `json:"modifier_ripples"`  // 按住修饰键点击时的波纹样式
+	Gestures         GestureConfig   `json:"gestures"`          // 双击、长按、拖动手势
+	Wheel            WheelStyle      `json:"wheel"`             // 滚轮指示器
+	Keystrokes       KeystrokeConfig `json:"keystrokes"`        // 按键显示
+	Particles        []EmitterConfig `json:"particles"`         // 粒子发射器，默认为空
+	Glow             GlowConfig      `json:"glow"`              // 辉光
+	Shaders          ShaderConfig    `json:"shaders"`           // 轨迹和波纹的 Kage 着色器
+	Brush            BrushConfig     `json:"brush"`             // 纹理笔刷
+	Layers           []TrailLayer    `json:"layers"`            // 轨迹图层，为空时只绘制一条轨迹
+	Halo             HaloConfig      `json:"halo"`              // 光标光环
+	Shake            ShakeConfig     `json:"shake"`             // 晃动找光标
+	Spotlight        SpotlightConfig `json:"spotlight"`         // 演示聚光灯
+	Ink              InkConfig       `json:"ink"`               // 屏幕批注
+	Laser            LaserConfig     `json:"laser"`             // 激光笔模式
+	Hotkeys          HotkeyConfig    `json:"hotkeys"`           // 全局热键
+	Language         string          `json:"language"`          // 语言: "auto", "en", "zh"
+
+	// 旧版按帧计算的参数，仅用于迁移旧配置文件，加载后会被换算并清零
+	DecaySpeed        float64 `json:"decay_speed,omitempty"`         // 每帧保留的生命比例
+	RippleGrowthSpeed float64 `json:"ripple_growth_speed,omitempty"` // 每帧扩散像素
+	RippleDecaySpeed  float64 `json:"ripple_decay_speed,omitempty"`  // 每帧减少的生命
 }
 
+// legacyTPS 旧版按帧参数对应的刷新率
+const legacyTPS = 60
+
 // DefaultConfig 返回默认配置
 func DefaultConfig() *Config {
 	return &Config{
-		TailColor:         [4]uint8{255, 0, 0, 255}, // 红色
-		TailLength:        20,
-		TailWidth:         8.0,
-		DecaySpeed:        0.95,
-		IsRainbow:         false,
-		IsRipple:          true,
-		RippleGrowthSpeed: 3.0,
-		RippleDecaySpeed:  0.04,
-		RippleWidth:       5.0,
-		Language:          "auto",
+		TailColor:        [4]uint8{255, 0, 0, 255}, // 红色
+		TailLength:       20,
+		TailWidth:        8.0,
+		TailLifetime:     1.0 / 3,
+		Smoothing:        SmoothingNone,
+		SmoothingTension: 0,
+		WidthProfile:     Profile{Shape: ProfileLinear},
+		AlphaProfile:     Profile{Shape: ProfileLinear},
+		IsRainbow:        false,
+		SpeedStyle:       defaultSpeedStyle(),
+		RainbowMode:      RainbowUniform,
+		RainbowSpace:     RainbowOKLCH,
+		RainbowPeriod:    4.0,
+		RainbowSpread:    0.5,
+		IsRipple:         true,
+		RippleGrowth:     180.0,
+		RippleDuration:   0.4,
+		RippleWidth:      5.0,
+		ButtonRipples:    defaultButtonRipples(),
+		ModifierRipples:  defaultModifierStyle(),
+		Gestures:         defaultGestureConfig(),
+		Wheel:            defaultWheelStyle(),
+		Keystrokes:       defaultKeystrokeConfig(),
+		Glow:             defaultGlowConfig(),
+		Shaders:          defaultShaderConfig(),
+		Brush:            defaultBrushConfig(),
+		Halo:             defaultHaloConfig(),
+		Shake:            defaultShakeConfig(),
+		Spotlight:        defaultSpotlightConfig(),
+		Ink:              defaultInkConfig(),
+		Laser:            defaultLaserConfig(),
+		Hotkeys:          defaultHotkeyConfig(),
+		Language:         "auto",
 	}
 }
 
@@ -44,33 +103,73 @@ func LoadConfig(filename string) (*Config, error) {
 	}
 	defer f.Close()
 
+	// 旧字段不预置默认值，只有文件里出现时才会被迁移
 	cfg := DefaultConfig()
 	if err := json.NewDecoder(f).Decode(cfg); err != nil {
 		return DefaultConfig(), err
 	}
 
+	cfg.migrateLegacy()
+
 	// 检查并设置默认值 (针对旧配置文件缺少新字段的情况)
-	if cfg.RippleGrowthSpeed == 0 {
-		cfg.RippleGrowthSpeed = 3.0
+	def := DefaultConfig()
+	if cfg.TailLifetime <= 0 {
+		cfg.TailLifetime = def.TailLifetime
+	}
+	if cfg.RippleGrowth <= 0 {
+		cfg.RippleGrowth = def.RippleGrowth
 	}
-	if cfg.RippleDecaySpeed == 0 {
-		cfg.RippleDecaySpeed = 0.04
+	if cfg.RippleDuration <= 0 {
+		cfg.RippleDuration = def.RippleDuration
 	}
 	if cfg.RippleWidth == 0 {
 		cfg.RippleWidth = 5.0
 	}
+	cfg.ButtonRipples.normalize()
+	for i := range cfg.Particles {
+		cfg.Particles[i].normalize()
+	}
+	cfg.WidthProfile.normalize()
+	cfg.AlphaProfile.normalize()
+	if cfg.RainbowMode == "" {
+		cfg.RainbowMode = def.RainbowMode
+	}
+	if cfg.RainbowSpace == "" {
+		cfg.RainbowSpace = def.RainbowSpace
+	}
+	if cfg.RainbowPeriod <= 0 {
+		cfg.RainbowPeriod = def.RainbowPeriod
+	}
+	if cfg.Smoothing == "" {
+		cfg.Smoothing = SmoothingNone
+	}
 	if cfg.Language == "" {
 		cfg.Language = "auto"
 	}
 
-	// 强制确保水波纹开启（响应用户恢复默认配置的请求）
-	if !cfg.IsRipple {
-		cfg.IsRipple = true
-	}
+	// 激光笔模式可能隐藏系统光标，每次启动时都从普通轨迹开始
+	cfg.Laser.Enabled = false
 
 	return cfg, nil
 }
 
+// migrateLegacy 把旧版按帧 (60 TPS) 计算的参数换算为按秒计算的参数
+func (c *Config) migrateLegacy() {
+	if c.DecaySpeed > 0 && c.DecaySpeed < 1 {
+		// 每帧减少 (1 - DecaySpeed)，生命从 1 衰减到 0 需要 1/(1-DecaySpeed) 帧
+		c.TailLifetime = 1 / (1 - c.DecaySpeed) / legacyTPS
+	}
+	if c.RippleGrowthSpeed > 0 {
+		c.RippleGrowth = c.RippleGrowthSpeed * legacyTPS
+	}
+	if c.RippleDecaySpeed > 0 {
+		c.RippleDuration = 1 / c.RippleDecaySpeed / legacyTPS
+	}
+	c.DecaySpeed = 0
+	c.RippleGrowthSpeed = 0
+	c.RippleDecaySpeed = 0
+}
+
 // SaveConfig 保存配置到文件
 func SaveConfig(filename string, cfg *Config) error {
 	f, err := os.Create(filename)
@@ -84,6 +183,50 @@ func SaveConfig(filename string, cfg *Config) error {
 	return encoder.Encode(cfg)
 }
 
+// Clone 返回配置的深拷贝，切片不与 c 共享
+// 设置窗口在自己的线程中修改副本，再把副本交给游戏线程应用
+// 逐个复制切片而不经过 JSON，设置窗口中输入的 NaN、Inf 也能复制；新增切片字段时需要在这里一并复制
+func (c *Config) Clone() *Config {
+	clone := *c
+	clone.TailGradient = slices.Clone(c.TailGradient)
+	clone.WidthProfile.Points = slices.Clone(c.WidthProfile.Points)
+	clone.AlphaProfile.Points = slices.Clone(c.AlphaProfile.Points)
+	clone.Particles = slices.Clone(c.Particles)
+	for i := range clone.Particles {
+		clone.Particles[i].Colors = slices.Clone(clone.Particles[i].Colors)
+	}
+	clone.Layers = slices.Clone(c.Layers)
+	for i := range clone.Layers {
+		clone.Layers[i].Gradient = slices.Clone(clone.Layers[i].Gradient)
+	}
+	return &clone
+}
+
+// ConfigEdit 设置窗口中的一次修改，Before 和 After 为修改前后的配置副本
+type ConfigEdit struct {
+	Before, After *Config
+}
+
+// Apply 把 Before 到 After 之间改变的字段写入 c，其余字段保留 c 当前的值
+// 设置窗口打开期间由热键、托盘菜单切换的开关和激光笔的自动关闭不会被窗口中的旧值还原
+func (e ConfigEdit) Apply(c *Config) {
+	applyChanged(reflect.ValueOf(c).Elem(), reflect.ValueOf(e.Before).Elem(), reflect.ValueOf(e.After).Elem())
+}
+
+// applyChanged 比较 before 和 after，把改变的部分写入 dst
+// 结构体按字段递归比较，切片、数组和其他值整体替换
+func applyChanged(dst, before, after reflect.Value) {
+	if dst.Kind() == reflect.Struct {
+		for i := 0; i < dst.NumField(); i++ {
+			applyChanged(dst.Field(i), before.Field(i), after.Field(i))
+		}
+		return
+	}
+	if !reflect.DeepEqual(before.Interface(), after.Interface()) {
+		dst.Set(after)
+	}
+}
+
 // GetColor 返回 color.RGBA 对象
 func (c *Config) GetColor() color.RGBA {
 	return color.RGBA{c.TailColor[0], c.TailColor[1], c.TailColor[2], c.TailColor[3]}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// sharedSlices 返回 a 和 b 中共享底层数组的非空切片的路径
+func sharedSlices(a, b reflect.Value, path string) []string {
+	var shared []string
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			shared = append(shared, sharedSlices(a.Field(i), b.Field(i), path+"."+a.Type().Field(i).Name)...)
+		}
+	case reflect.Array:
+		for i := 0; i < a.Len(); i++ {
+			shared = append(shared, sharedSlices(a.Index(i), b.Index(i), path)...)
+		}
+	case reflect.Slice:
+		if a.Len() > 0 && a.Pointer() == b.Pointer() {
+			shared = append(shared, path)
+		}
+		for i := 0; i < min(a.Len(), b.Len()); i++ {
+			shared = append(shared, sharedSlices(a.Index(i), b.Index(i), path)...)
+		}
+	}
+	return shared
+}
+
+func TestConfigClone(t *testing.T) {
+	c := DefaultConfig()
+	c.TailGradient = []GradientStop{{Pos: 0}, {Pos: 1}}
+	c.WidthProfile = Profile{Shape: ProfileCustom, Points: [][2]float64{{0, 1}, {1, 0}}}
+	c.AlphaProfile = Profile{Shape: ProfileCustom, Points: [][2]float64{{0, 1}, {1, 0}}}
+	c.Particles = []EmitterConfig{{Colors: []GradientStop{{Pos: 0}}}}
+	c.Layers = []TrailLayer{{Gradient: []GradientStop{{Pos: 0}}}}
+	// 设置窗口中可能输入的值，JSON 无法编码
+	c.TailWidth = math.NaN()
+	c.RippleWidth = math.Inf(1)
+
+	clone := c.Clone()
+	if shared := sharedSlices(reflect.ValueOf(*c), reflect.ValueOf(*clone), "Config"); len(shared) > 0 {
+		t.Errorf("clone shares slices with the original: %v", shared)
+	}
+	if !math.IsNaN(clone.TailWidth) || !math.IsInf(clone.RippleWidth, 1) {
+		t.Errorf("clone lost non-finite values: %v, %v", clone.TailWidth, clone.RippleWidth)
+	}
+	clone.TailWidth, clone.RippleWidth = c.TailWidth, c.RippleWidth
+	if !reflect.DeepEqual(c.Layers, clone.Layers) || !reflect.DeepEqual(c.Particles, clone.Particles) {
+		t.Error("clone differs from the original")
+	}
+}
+
+func TestConfigEditApply(t *testing.T) {
+	live := DefaultConfig()
+	before := live.Clone()
+
+	// 设置窗口打开期间用热键打开了聚光灯、切换了图层预设
+	live.Spotlight.Enabled = true
+	live.Layers = []TrailLayer{{Enabled: true, Length: 10}}
+
+	// 设置窗口修改了轨迹宽度和波纹颜色
+	after := before.Clone()
+	after.TailWidth = 20
+	after.ButtonRipples.Left.Color = [4]uint8{1, 2, 3, 255}
+	ConfigEdit{Before: before, After: after}.Apply(live)
+
+	if live.TailWidth != 20 || live.ButtonRipples.Left.Color != [4]uint8{1, 2, 3, 255} {
+		t.Errorf("edited fields not applied: width %v, color %v", live.TailWidth, live.ButtonRipples.Left.Color)
+	}
+	if !live.Spotlight.Enabled || len(live.Layers) != 1 {
+		t.Errorf("runtime toggles reverted: spotlight %v, layers %v", live.Spotlight.Enabled, live.Layers)
+	}
+	if live.ButtonRipples.Right != before.ButtonRipples.Right {
+		t.Error("unedited sibling field changed")
+	}
+
+	// 在设置窗口中关闭聚光灯时照常生效
+	after2 := after.Clone()
+	after2.Spotlight.Enabled = false
+	after.Spotlight.Enabled = true
+	ConfigEdit{Before: after, After: after2}.Apply(live)
+	if live.Spotlight.Enabled {
+		t.Error("spotlight edit in the window not applied")
+	}
+}
+
+func TestLoadLegacyConfig(t *testing.T) {
+	// 旧版按帧 (60 TPS) 计算参数的配置文件
+	const legacy = `{
+  "tail_color": [0, 128, 255, 255],
+  "tail_length": 30,
+  "tail_width": 10,
+  "decay_speed": 0.9,
+  "is_rainbow": false,
+  "is_ripple": false,
+  "ripple_growth_speed": 2,
+  "ripple_decay_speed": 0.05,
+  "ripple_width": 4,
+  "language": "en"
+}`
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 每帧保留 0.9 的生命需要 10 帧衰减完，每帧扩散 2 像素，每帧减少 0.05 的生命需要 20 帧
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"tail_lifetime", cfg.TailLifetime, 10.0 / 60},
+		{"ripple_growth", cfg.RippleGrowth, 2 * 60},
+		{"ripple_duration", cfg.RippleDuration, 20.0 / 60},
+	} {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+	if cfg.DecaySpeed != 0 || cfg.RippleGrowthSpeed != 0 || cfg.RippleDecaySpeed != 0 {
+		t.Errorf("legacy fields not cleared: %v %v %v", cfg.DecaySpeed, cfg.RippleGrowthSpeed, cfg.RippleDecaySpeed)
+	}
+
+	// 其他字段照常读取，关闭的波纹保持关闭
+	if cfg.TailColor != [4]uint8{0, 128, 255, 255} || cfg.TailLength != 30 || cfg.TailWidth != 10 ||
+		cfg.RippleWidth != 4 || cfg.Language != "en" {
+		t.Errorf("fields not loaded: %+v", cfg)
+	}
+	if cfg.IsRipple {
+		t.Error("is_ripple = false was overridden on load")
+	}
+}
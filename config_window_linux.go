@@ -0,0 +1,10 @@
+package main
+
+import (
+	"log"
+)
+
+// ShowConfigWindow 在 Linux 上暂无图形配置界面，请直接编辑 config.json
+func ShowConfigWindow(cfg *Config, onUpdate func()) {
+	log.Println("Config window is not available on Linux, edit config.json instead")
+}
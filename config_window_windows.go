@@ -0,0 +1,1296 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/lxn/walk"
+	. "github.com/lxn/walk/declarative"
+	"github.com/lxn/win"
+)
+
+// ShowConfigWindow 显示配置对话框
+// cfg 是游戏线程交来的副本，只在本线程中修改，每次修改后调用 onUpdate
+func ShowConfigWindow(cfg *Config, onUpdate func()) {
+	var mainWindow *walk.MainWindow
+	var db *walk.DataBinder
+
+	// 临时结构体用于数据绑定
+	type ConfigViewModel struct {
+		TailLength     float64
+		TailWidth      float64
+		TailLifetime   float64
+		Smoothing      string
+		Tension        float64
+		WidthShape     string
+		AlphaShape     string
+		LayerPreset    string
+		IsRainbow      bool
+		RainbowMode    string
+		RainbowSpace   string
+		RainbowPeriod  float64
+		RainbowSpread  float64
+		SpeedEnabled   bool
+		SpeedMin       float64
+		SpeedMax       float64
+		FastWidth      float64
+		FastBrighten   float64
+		IsRipple       bool
+		RippleGrowth   float64
+		RippleDuration float64
+		RippleWidth    float64
+		Gestures       bool
+		DoubleClick    float64
+		LongPress      float64
+		DragShape      string
+		WheelEnabled   bool
+		WheelSize      float64
+		WheelDuration  float64
+		KeysEnabled    bool
+		KeysPosition   string
+		KeysFont       string
+		KeysFontSize   float64
+		KeysDuration   float64
+		KeysTyping     bool
+		KeysPrivacy    bool
+		GlowEnabled    bool
+		GlowRadius     float64
+		GlowIntensity  float64
+		TrailShader    string
+		RippleShader   string
+		BrushPath      string
+		BrushMode      string
+		BrushTint      bool
+		HaloEnabled    bool
+		HaloStyle      string
+		HaloRadius     float64
+		HaloOpacity    float64
+		ShakeEnabled   bool
+		ShakeStyle     string
+		ShakeReversals int
+		ShakeDistance  float64
+		ShakeRadius    float64
+		SpotEnabled    bool
+		SpotShape      string
+		SpotRadius     float64
+		SpotWidth      float64
+		SpotHeight     float64
+		SpotFeather    float64
+		SpotDim        float64
+		InkEnabled     bool
+		InkHotkey      string
+		InkTool        string
+		InkWidth       float64
+		InkFadeAfter   float64
+		LaserEnabled   bool
+		LaserWidth     float64
+		LaserDot       float64
+		LaserHide      bool
+		LaserAutoOff   float64
+		HkPause        string
+		HkRipples      string
+		HkPreset       string
+		HkSpotlight    string
+		HkLaser        string
+		HkInkUndo      string
+		HkInkClear     string
+		HkConfig       string
+		Red            int
+		Green          int
+		Blue           int
+		Language       string
+	}
+
+	// 轨迹图层预设，config.json 中自定义的图层显示为"自定义"，不会被覆盖
+	const layerPresetCustom = "custom"
+	layerPreset, builtinLayers := trailLayerPresetName(cfg.Layers)
+	if !builtinLayers {
+		layerPreset = layerPresetCustom
+	}
+
+	vm := &ConfigViewModel{
+		TailLength:     float64(cfg.TailLength),
+		TailWidth:      cfg.TailWidth,
+		TailLifetime:   cfg.TailLifetime,
+		Smoothing:      cfg.Smoothing,
+		Tension:        cfg.SmoothingTension,
+		WidthShape:     cfg.WidthProfile.Shape,
+		AlphaShape:     cfg.AlphaProfile.Shape,
+		LayerPreset:    layerPreset,
+		IsRainbow:      cfg.IsRainbow,
+		RainbowMode:    cfg.RainbowMode,
+		RainbowSpace:   cfg.RainbowSpace,
+		RainbowPeriod:  cfg.RainbowPeriod,
+		RainbowSpread:  cfg.RainbowSpread,
+		SpeedEnabled:   cfg.SpeedStyle.Enabled,
+		SpeedMin:       cfg.SpeedStyle.MinSpeed,
+		SpeedMax:       cfg.SpeedStyle.MaxSpeed,
+		FastWidth:      cfg.SpeedStyle.Width[1],
+		FastBrighten:   cfg.SpeedStyle.Brighten[1],
+		IsRipple:       cfg.IsRipple,
+		RippleGrowth:   cfg.RippleGrowth,
+		RippleDuration: cfg.RippleDuration,
+		RippleWidth:    cfg.RippleWidth,
+		Gestures:       cfg.Gestures.Enabled,
+		DoubleClick:    cfg.Gestures.DoubleClickTime,
+		LongPress:      cfg.Gestures.LongPressTime,
+		DragShape:      cfg.Gestures.DragShape,
+		WheelEnabled:   cfg.Wheel.Enabled,
+		WheelSize:      cfg.Wheel.Size,
+		WheelDuration:  cfg.Wheel.Duration,
+		KeysEnabled:    cfg.Keystrokes.Enabled,
+		KeysPosition:   cfg.Keystrokes.Position,
+		KeysFont:       cfg.Keystrokes.FontPath,
+		KeysFontSize:   cfg.Keystrokes.FontSize,
+		KeysDuration:   cfg.Keystrokes.Duration,
+		KeysTyping:     cfg.Keystrokes.ShowTyping,
+		KeysPrivacy:    cfg.Keystrokes.Privacy,
+		GlowEnabled:    cfg.Glow.Enabled,
+		GlowRadius:     cfg.Glow.Radius,
+		GlowIntensity:  cfg.Glow.Intensity,
+		TrailShader:    cfg.Shaders.Trail,
+		RippleShader:   cfg.Shaders.Ripple,
+		BrushPath:      cfg.Brush.Path,
+		BrushMode:      cfg.Brush.Mode,
+		BrushTint:      cfg.Brush.Tint,
+		HaloEnabled:    cfg.Halo.Enabled,
+		HaloStyle:      cfg.Halo.Style,
+		HaloRadius:     cfg.Halo.Radius,
+		HaloOpacity:    cfg.Halo.Opacity,
+		ShakeEnabled:   cfg.Shake.Enabled,
+		ShakeStyle:     cfg.Shake.Style,
+		ShakeReversals: cfg.Shake.Reversals,
+		ShakeDistance:  cfg.Shake.MinDistance,
+		ShakeRadius:    cfg.Shake.Radius,
+		SpotEnabled:    cfg.Spotlight.Enabled,
+		SpotShape:      cfg.Spotlight.Shape,
+		SpotRadius:     cfg.Spotlight.Radius,
+		SpotWidth:      cfg.Spotlight.Width,
+		SpotHeight:     cfg.Spotlight.Height,
+		SpotFeather:    cfg.Spotlight.Feather,
+		SpotDim:        cfg.Spotlight.Dim,
+		InkEnabled:     cfg.Ink.Enabled,
+		InkHotkey:      cfg.Ink.Hotkey,
+		InkTool:        cfg.Ink.Tool,
+		InkWidth:       cfg.Ink.Width,
+		InkFadeAfter:   cfg.Ink.FadeAfter,
+		LaserEnabled:   cfg.Laser.Enabled,
+		LaserWidth:     cfg.Laser.Width,
+		LaserDot:       cfg.Laser.DotRadius,
+		LaserHide:      cfg.Laser.HideCursor,
+		LaserAutoOff:   cfg.Laser.AutoOff,
+		HkPause:        cfg.Hotkeys.Pause,
+		HkRipples:      cfg.Hotkeys.ToggleRipples,
+		HkPreset:       cfg.Hotkeys.CyclePreset,
+		HkSpotlight:    cfg.Hotkeys.Spotlight,
+		HkLaser:        cfg.Hotkeys.Laser,
+		HkInkUndo:      cfg.Hotkeys.InkUndo,
+		HkInkClear:     cfg.Hotkeys.InkClear,
+		HkConfig:       cfg.Hotkeys.OpenConfig,
+		Red:            int(cfg.TailColor[0]),
+		Green:          int(cfg.TailColor[1]),
+		Blue:           int(cfg.TailColor[2]),
+		Language:       cfg.Language,
+	}
+
+	// 下拉框选项
+	type Option struct {
+		Name  string
+		Value string
+	}
+
+	// 语言选项
+	langOptions := []*Option{
+		{Name: T("LangAuto"), Value: "auto"},
+		{Name: T("LangZh"), Value: "zh"},
+		{Name: T("LangEn"), Value: "en"},
+	}
+
+	// 平滑模式选项
+	smoothingOptions := []*Option{
+		{Name: T("SmoothNone"), Value: SmoothingNone},
+		{Name: T("SmoothCatmullRom"), Value: SmoothingCatmullRom},
+		{Name: T("SmoothCentripetal"), Value: SmoothingCentripetal},
+	}
+
+	// 彩虹模式选项
+	rainbowModeOptions := []*Option{
+		{Name: T("RainbowUniform"), Value: RainbowUniform},
+		{Name: T("RainbowTrail"), Value: RainbowTrail},
+		{Name: T("RainbowSpeed"), Value: RainbowSpeed},
+	}
+	rainbowSpaceOptions := []*Option{
+		{Name: "OKLCH", Value: RainbowOKLCH},
+		{Name: "HSV", Value: RainbowHSV},
+	}
+
+	// 宽度/透明度曲线选项，自定义控制点只能在 config.json 中编辑
+	profileOptions := []*Option{
+		{Name: T("ProfileLinear"), Value: ProfileLinear},
+		{Name: T("ProfileConstant"), Value: ProfileConstant},
+		{Name: T("ProfileEaseIn"), Value: ProfileEaseIn},
+		{Name: T("ProfileEaseOut"), Value: ProfileEaseOut},
+		{Name: T("ProfileBlade"), Value: ProfileBlade},
+		{Name: T("ProfileCustom"), Value: ProfileCustom},
+	}
+
+	// 拖动图形选项
+	dragShapeOptions := []*Option{
+		{Name: T("DragRect"), Value: DragShapeRect},
+		{Name: T("DragLine"), Value: DragShapeLine},
+	}
+
+	// 按键气泡位置选项
+	keysPositionOptions := []*Option{
+		{Name: T("PosCursor"), Value: KeystrokeAtCursor},
+		{Name: T("PosTopLeft"), Value: KeystrokeTopLeft},
+		{Name: T("PosTopRight"), Value: KeystrokeTopRight},
+		{Name: T("PosBottomLeft"), Value: KeystrokeBottomLeft},
+		{Name: T("PosBottomRight"), Value: KeystrokeBottomRight},
+	}
+
+	// 笔刷模式选项
+	brushModeOptions := []*Option{
+		{Name: T("BrushStretch"), Value: BrushStretch},
+		{Name: T("BrushTile"), Value: BrushTile},
+		{Name: T("BrushStamp"), Value: BrushStamp},
+	}
+
+	// 光环样式选项
+	haloStyleOptions := []*Option{
+		{Name: T("HaloRing"), Value: HaloRing},
+		{Name: T("HaloFill"), Value: HaloFill},
+	}
+
+	// 聚光灯形状选项
+	spotlightShapeOptions := []*Option{
+		{Name: T("SpotlightCircle"), Value: SpotlightCircle},
+		{Name: T("SpotlightRect"), Value: SpotlightRect},
+	}
+
+	// 批注工具选项
+	inkToolOptions := []*Option{
+		{Name: T("InkPen"), Value: InkPen},
+		{Name: T("InkHighlighter"), Value: InkHighlighter},
+		{Name: T("InkArrow"), Value: InkArrow},
+		{Name: T("InkRect"), Value: InkRect},
+		{Name: T("InkEllipse"), Value: InkEllipse},
+	}
+
+	// 轨迹图层选项
+	layerOptions := []*Option{
+		{Name: T("LayerNone"), Value: LayerPresetNone},
+		{Name: T("LayerGlowCore"), Value: LayerPresetGlowCore},
+		{Name: T("LayerGhost"), Value: LayerPresetGhost},
+	}
+	if !builtinLayers {
+		layerOptions = append(layerOptions, &Option{Name: T("LayerCustom"), Value: layerPresetCustom})
+	}
+
+	// 着色器选项：内置和 shaders 目录中的着色器
+	shaderOptions := []*Option{{Name: T("ShaderNone"), Value: ""}}
+	for _, name := range availableShaders(cfg.Shaders.Dir) {
+		shaderOptions = append(shaderOptions, &Option{Name: name, Value: name})
+	}
+
+	// 更新配置的回调
+	var update func()
+
+	// 光环颜色选择
+	var haloColors [16]win.COLORREF
+	pickHaloColor := func(c *[4]uint8) func() {
+		return func() {
+			if chooseColor(mainWindow, &haloColors, c) {
+				c[3] = 255
+				update()
+			}
+		}
+	}
+	gradientEditor := newGradientEditor(cfg, &mainWindow, func() { update() })
+	rippleEditor := newRippleEditor(cfg, &mainWindow, func() { update() })
+	update = func() {
+		if err := db.Submit(); err != nil {
+			log.Println(err)
+			return
+		}
+
+		cfg.TailLength = int(vm.TailLength)
+		cfg.TailWidth = vm.TailWidth
+		cfg.TailLifetime = vm.TailLifetime
+		cfg.Smoothing = vm.Smoothing
+		cfg.SmoothingTension = vm.Tension
+		cfg.WidthProfile.Shape = vm.WidthShape
+		cfg.AlphaProfile.Shape = vm.AlphaShape
+		if vm.LayerPreset != layerPresetCustom {
+			cfg.Layers = trailLayerPreset(vm.LayerPreset)
+		}
+		cfg.IsRainbow = vm.IsRainbow
+		cfg.RainbowMode = vm.RainbowMode
+		cfg.RainbowSpace = vm.RainbowSpace
+		cfg.RainbowPeriod = vm.RainbowPeriod
+		cfg.RainbowSpread = vm.RainbowSpread
+		cfg.SpeedStyle.Enabled = vm.SpeedEnabled
+		cfg.SpeedStyle.MinSpeed = vm.SpeedMin
+		cfg.SpeedStyle.MaxSpeed = vm.SpeedMax
+		cfg.SpeedStyle.Width[1] = vm.FastWidth
+		cfg.SpeedStyle.Brighten[1] = vm.FastBrighten
+		cfg.IsRipple = vm.IsRipple
+		cfg.RippleGrowth = vm.RippleGrowth
+		cfg.RippleDuration = vm.RippleDuration
+		cfg.RippleWidth = vm.RippleWidth
+		cfg.Gestures.Enabled = vm.Gestures
+		cfg.Gestures.DoubleClickTime = vm.DoubleClick
+		cfg.Gestures.LongPressTime = vm.LongPress
+		cfg.Gestures.DragShape = vm.DragShape
+		cfg.Wheel.Enabled = vm.WheelEnabled
+		cfg.Wheel.Size = vm.WheelSize
+		cfg.Wheel.Duration = vm.WheelDuration
+		cfg.Keystrokes.Enabled = vm.KeysEnabled
+		cfg.Keystrokes.Position = vm.KeysPosition
+		cfg.Keystrokes.FontPath = vm.KeysFont
+		cfg.Keystrokes.FontSize = vm.KeysFontSize
+		cfg.Keystrokes.Duration = vm.KeysDuration
+		cfg.Keystrokes.ShowTyping = vm.KeysTyping
+		cfg.Keystrokes.Privacy = vm.KeysPrivacy
+		cfg.Glow.Enabled = vm.GlowEnabled
+		cfg.Glow.Radius = vm.GlowRadius
+		cfg.Glow.Intensity = vm.GlowIntensity
+		cfg.Shaders.Trail = vm.TrailShader
+		cfg.Shaders.Ripple = vm.RippleShader
+		cfg.Brush.Path = vm.BrushPath
+		cfg.Brush.Mode = vm.BrushMode
+		cfg.Brush.Tint = vm.BrushTint
+		cfg.Halo.Enabled = vm.HaloEnabled
+		cfg.Halo.Style = vm.HaloStyle
+		cfg.Halo.Radius = vm.HaloRadius
+		cfg.Halo.Opacity = vm.HaloOpacity
+		cfg.Shake.Enabled = vm.ShakeEnabled
+		cfg.Shake.Style = vm.ShakeStyle
+		cfg.Shake.Reversals = vm.ShakeReversals
+		cfg.Shake.MinDistance = vm.ShakeDistance
+		cfg.Shake.Radius = vm.ShakeRadius
+		cfg.Spotlight.Enabled = vm.SpotEnabled
+		cfg.Spotlight.Shape = vm.SpotShape
+		cfg.Spotlight.Radius = vm.SpotRadius
+		cfg.Spotlight.Width = vm.SpotWidth
+		cfg.Spotlight.Height = vm.SpotHeight
+		cfg.Spotlight.Feather = vm.SpotFeather
+		cfg.Spotlight.Dim = vm.SpotDim
+		cfg.Ink.Enabled = vm.InkEnabled
+		cfg.Ink.Hotkey = vm.InkHotkey
+		cfg.Ink.Tool = vm.InkTool
+		cfg.Ink.Width = vm.InkWidth
+		cfg.Ink.FadeAfter = vm.InkFadeAfter
+		cfg.Laser.Enabled = vm.LaserEnabled
+		cfg.Laser.Width = vm.LaserWidth
+		cfg.Laser.DotRadius = vm.LaserDot
+		cfg.Laser.HideCursor = vm.LaserHide
+		cfg.Laser.AutoOff = vm.LaserAutoOff
+		cfg.Hotkeys = HotkeyConfig{
+			Pause:         vm.HkPause,
+			ToggleRipples: vm.HkRipples,
+			CyclePreset:   vm.HkPreset,
+			Spotlight:     vm.HkSpotlight,
+			Laser:         vm.HkLaser,
+			InkUndo:       vm.HkInkUndo,
+			InkClear:      vm.HkInkClear,
+			OpenConfig:    vm.HkConfig,
+		}
+		cfg.TailColor[0] = uint8(vm.Red)
+		cfg.TailColor[1] = uint8(vm.Green)
+		cfg.TailColor[2] = uint8(vm.Blue)
+		cfg.Language = vm.Language
+
+		// 应用语言设置 (注意：当前窗口的文本不会立即刷新，但下次打开或托盘菜单会生效)
+		SetLanguage(cfg.Language)
+
+		if onUpdate != nil {
+			onUpdate()
+		}
+	}
+
+	if err := (MainWindow{
+		AssignTo: &mainWindow,
+		Title:    T("Title"),
+		Size:     Size{Width: 380, Height: 620}, // 设置按页签分组
+		Layout:   VBox{},
+		DataBinder: DataBinder{
+			AssignTo:       &db,
+			Name:           "vm",
+			DataSource:     vm,
+			ErrorPresenter: ToolTipErrorPresenter{},
+		},
+		Children: []Widget{
+			GroupBox{
+				Title:  T("Language"),
+				Layout: HBox{},
+				Children: []Widget{
+					Label{Text: T("Language")},
+					ComboBox{
+						Value:                 Bind("Language"),
+						Model:                 langOptions,
+						BindingMember:         "Value",
+						DisplayMember:         "Name",
+						OnCurrentIndexChanged: update, // 选择即生效
+					},
+				},
+			},
+
+			TabWidget{
+				Pages: []TabPage{
+					{
+						Title:  T("TabTrail"),
+						Layout: VBox{},
+						Children: []Widget{
+							GroupBox{
+								Title:  T("Appearance"),
+								Layout: Grid{Columns: 2},
+								Children: []Widget{
+									Label{Text: T("Length")},
+									NumberEdit{
+										Value:          Bind("TailLength"),
+										OnValueChanged: update,
+										Decimals:       0,
+									},
+
+									Label{Text: T("Width")},
+									NumberEdit{
+										Value:          Bind("TailWidth"),
+										OnValueChanged: update,
+										Decimals:       1,
+									},
+
+									Label{Text: T("Lifetime")},
+									NumberEdit{
+										Value:          Bind("TailLifetime"),
+										OnValueChanged: update,
+										Decimals:       2,
+									},
+
+									Label{Text: T("Smoothing")},
+									ComboBox{
+										Value:                 Bind("Smoothing"),
+										Model:                 smoothingOptions,
+										BindingMember:         "Value",
+										DisplayMember:         "Name",
+										OnCurrentIndexChanged: update,
+									},
+
+									Label{Text: T("Tension")},
+									NumberEdit{
+										Value:          Bind("Tension"),
+										MinValue:       0,
+										MaxValue:       1,
+										OnValueChanged: update,
+										Decimals:       2,
+										Enabled:        Bind("vm.Smoothing != 'none'"),
+									},
+
+									Label{Text: T("WidthProfile")},
+									ComboBox{
+										Value:                 Bind("WidthShape"),
+										Model:                 profileOptions,
+										BindingMember:         "Value",
+										DisplayMember:         "Name",
+										OnCurrentIndexChanged: update,
+									},
+
+									Label{Text: T("AlphaProfile")},
+									ComboBox{
+										Value:                 Bind("AlphaShape"),
+										Model:                 profileOptions,
+										BindingMember:         "Value",
+										DisplayMember:         "Name",
+										OnCurrentIndexChanged: update,
+									},
+
+									Label{Text: T("TrailLayers")},
+									ComboBox{
+										Value:                 Bind("LayerPreset"),
+										Model:                 layerOptions,
+										BindingMember:         "Value",
+										DisplayMember:         "Name",
+										OnCurrentIndexChanged: update,
+									},
+								},
+							},
+							GroupBox{
+								Title:  T("SpeedStyle"),
+								Layout: Grid{Columns: 2},
+								Children: []Widget{
+									CheckBox{
+										Text:             T("SpeedEnabled"),
+										Checked:          Bind("SpeedEnabled"),
+										OnCheckedChanged: update,
+										ColumnSpan:       2,
+									},
+									Label{Text: T("SpeedMin")},
+									NumberEdit{
+										Value:          Bind("SpeedMin"),
+										OnValueChanged: update,
+										Decimals:       0,
+										Enabled:        Bind("vm.SpeedEnabled"),
+									},
+									Label{Text: T("SpeedMax")},
+									NumberEdit{
+										Value:          Bind("SpeedMax"),
+										OnValueChanged: update,
+										Decimals:       0,
+										Enabled:        Bind("vm.SpeedEnabled"),
+									},
+									Label{Text: T("FastWidth")},
+									NumberEdit{
+										Value:          Bind("FastWidth"),
+										OnValueChanged: update,
+										Decimals:       2,
+										Enabled:        Bind("vm.SpeedEnabled"),
+									},
+									Label{Text: T("FastBrighten")},
+									NumberEdit{
+										Value:          Bind("FastBrighten"),
+										MinValue:       0,
+										MaxValue:       1,
+										OnValueChanged: update,
+										Decimals:       2,
+										Enabled:        Bind("vm.SpeedEnabled"),
+									},
+								},
+							},
+							VSpacer{},
+						},
+					},
+					{
+						Title:  T("TabColor"),
+						Layout: VBox{},
+						Children: []Widget{
+							GroupBox{
+								Title:  T("ColorEffects"),
+								Layout: Grid{Columns: 2},
+								Children: []Widget{
+									CheckBox{
+										Text:             T("RainbowMode"),
+										Checked:          Bind("IsRainbow"),
+										OnCheckedChanged: update,
+										ColumnSpan:       2,
+									},
+
+									Label{Text: T("RainbowStyle")},
+									ComboBox{
+										Value:                 Bind("RainbowMode"),
+										Model:                 rainbowModeOptions,
+										BindingMember:         "Value",
+										DisplayMember:         "Name",
+										OnCurrentIndexChanged: update,
+										Enabled:               Bind("vm.IsRainbow"),
+									},
+
+									Label{Text: T("RainbowSpace")},
+									ComboBox{
+										Value:                 Bind("RainbowSpace"),
+										Model:                 rainbowSpaceOptions,
+										BindingMember:         "Value",
+										DisplayMember:         "Name",
+										OnCurrentIndexChanged: update,
+										Enabled:               Bind("vm.IsRainbow"),
+									},
+
+									Label{Text: T("RainbowPeriod")},
+									NumberEdit{
+										Value:          Bind("RainbowPeriod"),
+										MinValue:       0.1,
+										MaxValue:       60,
+										OnValueChanged: update,
+										Decimals:       1,
+										Enabled:        Bind("vm.IsRainbow"),
+									},
+
+									Label{Text: T("RainbowSpread")},
+									NumberEdit{
+										Value:          Bind("RainbowSpread"),
+										MinValue:       0,
+										MaxValue:       4,
+										OnValueChanged: update,
+										Decimals:       2,
+										Enabled:        Bind("vm.IsRainbow && vm.RainbowMode == 'trail'"),
+									},
+
+									Label{Text: T("Red")},
+									Slider{
+										Value:          Bind("Red"),
+										MinValue:       0,
+										MaxValue:       255,
+										OnValueChanged: update,
+										Enabled:        Bind("!vm.IsRainbow"),
+									},
+
+									Label{Text: T("Green")},
+									Slider{
+										Value:          Bind("Green"),
+										MinValue:       0,
+										MaxValue:       255,
+										OnValueChanged: update,
+										Enabled:        Bind("!vm.IsRainbow"),
+									},
+
+									Label{Text: T("Blue")},
+									Slider{
+										Value:          Bind("Blue"),
+										MinValue:       0,
+										MaxValue:       255,
+										OnValueChanged: update,
+										Enabled:        Bind("!vm.IsRainbow"),
+									},
+								},
+							},
+
+							gradientEditor.Widget(),
+							VSpacer{},
+						},
+					},
+					{
+						Title:  T("TabRipple"),
+						Layout: VBox{},
+						Children: []Widget{
+							GroupBox{
+								Title:  T("RippleSettings"),
+								Layout: Grid{Columns: 2},
+								Children: []Widget{
+									CheckBox{
+										Text:             T("ClickRipple"),
+										Checked:          Bind("IsRipple"),
+										OnCheckedChanged: update,
+										ColumnSpan:       2,
+									},
+									Label{Text: T("RippleGrowth")},
+									NumberEdit{
+										Value:          Bind("RippleGrowth"),
+										OnValueChanged: update,
+										Decimals:       0,
+										Enabled:        Bind("vm.IsRipple"),
+									},
+									Label{Text: T("RippleDuration")},
+									NumberEdit{
+										Value:          Bind("RippleDuration"),
+										OnValueChanged: update,
+										Decimals:       2,
+										Enabled:        Bind("vm.IsRipple"),
+									},
+									Label{Text: T("RippleWidth")},
+									NumberEdit{
+										Value:          Bind("RippleWidth"),
+										OnValueChanged: update,
+										Decimals:       1,
+										Enabled:        Bind("vm.IsRipple"),
+									},
+								},
+							},
+
+							rippleEditor.Widget(),
+							rippleEditor.ModifierWidget(),
+							VSpacer{},
+						},
+					},
+					{
+						Title:  T("TabInput"),
+						Layout: VBox{},
+						Children: []Widget{
+							GroupBox{
+								Title:  T("Gestures"),
+								Layout: Grid{Columns: 2},
+								Children: []Widget{
+									CheckBox{
+										Text:             T("GesturesEnabled"),
+										Checked:          Bind("Gestures"),
+										OnCheckedChanged: update,
+										ColumnSpan:       2,
+									},
+									Label{Text: T("DoubleClickTime")},
+									NumberEdit{
+										Value:          Bind("DoubleClick"),
+										MinValue:       0.1,
+										MaxValue:       2,
+										OnValueChanged: update,
+										Decimals:       2,
+										Enabled:        Bind("vm.Gestures"),
+									},
+									Label{Text: T("LongPressTime")},
+									NumberEdit{
+										Value:          Bind("LongPress"),
+										MinValue:       0.1,
+										MaxValue:       5,
+										OnValueChanged: update,
+										Decimals:       2,
+										Enabled:        Bind("vm.Gestures"),
+									},
+									Label{Text: T("DragShape")},
+									ComboBox{
+										Value:                 Bind("DragShape"),
+										Model:                 dragShapeOptions,
+										BindingMember:         "Value",
+										DisplayMember:         "Name",
+										OnCurrentIndexChanged: update,
+										Enabled:               Bind("vm.Gestures"),
+									},
+								},
+							},
+							GroupBox{
+								Title:  T("WheelIndicator"),
+								Layout: Grid{Columns: 2},
+								Children: []Widget{
+									CheckBox{
+										Text:             T("WheelEnabled"),
+										Checked:          Bind("WheelEnabled"),
+										OnCheckedChanged: update,
+										ColumnSpan:       2,
+									},
+									Label{Text: T("WheelSize")},
+									NumberEdit{
+										Value:          Bind("WheelSize"),
+										MinValue:       2,
+										MaxValue:       100,
+										OnValueChanged: update,
+										Decimals:       0,
+										Enabled:        Bind("vm.WheelEnabled"),
+									},
+									Label{Text: T("WheelDuration")},
+									NumberEdit{
+										Value:          Bind("WheelDuration"),
+										MinValue:       0.05,
+										MaxValue:       5,
+										OnValueChanged: update,
+										Decimals:       2,
+										Enabled:        Bind("vm.WheelEnabled"),
+									},
+								},
+							},
+							VSpacer{},
+						},
+					},
+					{
+						Title:  T("TabKeys"),
+						Layout: VBox{},
+						Children: []Widget{
+							GroupBox{
+								Title:  T("Keystrokes"),
+								Layout: Grid{Columns: 2},
+								Children: []Widget{
+									CheckBox{
+										Text:             T("KeysEnabled"),
+										Checked:          Bind("KeysEnabled"),
+										OnCheckedChanged: update,
+										ColumnSpan:       2,
+									},
+									Label{Text: T("KeysPosition")},
+									ComboBox{
+										Value:                 Bind("KeysPosition"),
+										Model:                 keysPositionOptions,
+										BindingMember:         "Value",
+										DisplayMember:         "Name",
+										OnCurrentIndexChanged: update,
+										Enabled:               Bind("vm.KeysEnabled"),
+									},
+									Label{Text: T("KeysFont")},
+									LineEdit{
+										Text:              Bind("KeysFont"),
+										OnEditingFinished: update,
+										Enabled:           Bind("vm.KeysEnabled"),
+									},
+									Label{Text: T("KeysFontSize")},
+									NumberEdit{
+										Value:          Bind("KeysFontSize"),
+										MinValue:       6,
+										MaxValue:       96,
+										OnValueChanged: update,
+										Decimals:       0,
+										Enabled:        Bind("vm.KeysEnabled"),
+									},
+									Label{Text: T("KeysDuration")},
+									NumberEdit{
+										Value:          Bind("KeysDuration"),
+										MinValue:       0.2,
+										MaxValue:       10,
+										OnValueChanged: update,
+										Decimals:       1,
+										Enabled:        Bind("vm.KeysEnabled"),
+									},
+									CheckBox{
+										Text:             T("KeysTyping"),
+										Checked:          Bind("KeysTyping"),
+										OnCheckedChanged: update,
+										ColumnSpan:       2,
+										Enabled:          Bind("vm.KeysEnabled"),
+									},
+									CheckBox{
+										Text:             T("KeysPrivacy"),
+										Checked:          Bind("KeysPrivacy"),
+										OnCheckedChanged: update,
+										ColumnSpan:       2,
+										Enabled:          Bind("vm.KeysEnabled"),
+									},
+								},
+							},
+							VSpacer{},
+						},
+					},
+					{
+						Title:  T("TabEffects"),
+						Layout: VBox{},
+						Children: []Widget{
+							GroupBox{
+								Title:  T("Glow"),
+								Layout: Grid{Columns: 2},
+								Children: []Widget{
+									CheckBox{
+										Text:             T("GlowEnabled"),
+										Checked:          Bind("GlowEnabled"),
+										OnCheckedChanged: update,
+										ColumnSpan:       2,
+									},
+									Label{Text: T("GlowRadius")},
+									NumberEdit{
+										Value:          Bind("GlowRadius"),
+										MinValue:       1,
+										MaxValue:       100,
+										OnValueChanged: update,
+										Decimals:       0,
+										Enabled:        Bind("vm.GlowEnabled"),
+									},
+									Label{Text: T("GlowIntensity")},
+									NumberEdit{
+										Value:          Bind("GlowIntensity"),
+										MinValue:       0.1,
+										MaxValue:       5,
+										OnValueChanged: update,
+										Decimals:       1,
+										Enabled:        Bind("vm.GlowEnabled"),
+									},
+								},
+							},
+							GroupBox{
+								Title:  T("Shaders"),
+								Layout: Grid{Columns: 2},
+								Children: []Widget{
+									Label{Text: T("TrailShader")},
+									ComboBox{
+										Value:                 Bind("TrailShader"),
+										Model:                 shaderOptions,
+										BindingMember:         "Value",
+										DisplayMember:         "Name",
+										OnCurrentIndexChanged: update,
+									},
+									Label{Text: T("RippleShader")},
+									ComboBox{
+										Value:                 Bind("RippleShader"),
+										Model:                 shaderOptions,
+										BindingMember:         "Value",
+										DisplayMember:         "Name",
+										OnCurrentIndexChanged: update,
+									},
+								},
+							},
+							GroupBox{
+								Title:  T("Brush"),
+								Layout: Grid{Columns: 2},
+								Children: []Widget{
+									Label{Text: T("BrushPath")},
+									LineEdit{
+										Text:              Bind("BrushPath"),
+										OnEditingFinished: update,
+									},
+									Label{Text: T("BrushMode")},
+									ComboBox{
+										Value:                 Bind("BrushMode"),
+										Model:                 brushModeOptions,
+										BindingMember:         "Value",
+										DisplayMember:         "Name",
+										OnCurrentIndexChanged: update,
+									},
+									CheckBox{
+										Text:             T("BrushTint"),
+										Checked:          Bind("BrushTint"),
+										OnCheckedChanged: update,
+										ColumnSpan:       2,
+									},
+								},
+							},
+							particleWidget(cfg, func() { update() }),
+							VSpacer{},
+						},
+					},
+					{
+						Title:  T("TabCursor"),
+						Layout: VBox{},
+						Children: []Widget{
+							GroupBox{
+								Title:  T("Halo"),
+								Layout: Grid{Columns: 2},
+								Children: []Widget{
+									CheckBox{
+										Text:             T("HaloEnabled"),
+										Checked:          Bind("HaloEnabled"),
+										OnCheckedChanged: update,
+										ColumnSpan:       2,
+									},
+									Label{Text: T("HaloStyle")},
+									ComboBox{
+										Value:                 Bind("HaloStyle"),
+										Model:                 haloStyleOptions,
+										BindingMember:         "Value",
+										DisplayMember:         "Name",
+										OnCurrentIndexChanged: update,
+										Enabled:               Bind("vm.HaloEnabled"),
+									},
+									Label{Text: T("HaloRadius")},
+									NumberEdit{
+										Value:          Bind("HaloRadius"),
+										MinValue:       4,
+										MaxValue:       300,
+										OnValueChanged: update,
+										Decimals:       0,
+										Enabled:        Bind("vm.HaloEnabled"),
+									},
+									Label{Text: T("HaloOpacity")},
+									NumberEdit{
+										Value:          Bind("HaloOpacity"),
+										MinValue:       0.05,
+										MaxValue:       1,
+										OnValueChanged: update,
+										Decimals:       2,
+										Enabled:        Bind("vm.HaloEnabled"),
+									},
+									PushButton{
+										Text:      T("HaloColor"),
+										OnClicked: pickHaloColor(&cfg.Halo.Color),
+										Enabled:   Bind("vm.HaloEnabled"),
+									},
+									PushButton{
+										Text:      T("HaloPressedColor"),
+										OnClicked: pickHaloColor(&cfg.Halo.PressedColor),
+										Enabled:   Bind("vm.HaloEnabled"),
+									},
+								},
+							},
+							GroupBox{
+								Title:  T("Shake"),
+								Layout: Grid{Columns: 2},
+								Children: []Widget{
+									CheckBox{
+										Text:             T("ShakeEnabled"),
+										Checked:          Bind("ShakeEnabled"),
+										OnCheckedChanged: update,
+										ColumnSpan:       2,
+									},
+									Label{Text: T("ShakeStyle")},
+									ComboBox{
+										Value:                 Bind("ShakeStyle"),
+										Model:                 haloStyleOptions,
+										BindingMember:         "Value",
+										DisplayMember:         "Name",
+										OnCurrentIndexChanged: update,
+										Enabled:               Bind("vm.ShakeEnabled"),
+									},
+									Label{Text: T("ShakeReversals")},
+									NumberEdit{
+										Value:          Bind("ShakeReversals"),
+										MinValue:       2,
+										MaxValue:       10,
+										OnValueChanged: update,
+										Decimals:       0,
+										Enabled:        Bind("vm.ShakeEnabled"),
+									},
+									Label{Text: T("ShakeDistance")},
+									NumberEdit{
+										Value:          Bind("ShakeDistance"),
+										MinValue:       10,
+										MaxValue:       400,
+										OnValueChanged: update,
+										Decimals:       0,
+										Enabled:        Bind("vm.ShakeEnabled"),
+									},
+									Label{Text: T("ShakeRadius")},
+									NumberEdit{
+										Value:          Bind("ShakeRadius"),
+										MinValue:       20,
+										MaxValue:       400,
+										OnValueChanged: update,
+										Decimals:       0,
+										Enabled:        Bind("vm.ShakeEnabled"),
+									},
+									PushButton{
+										Text:       T("ShakeColor"),
+										OnClicked:  pickHaloColor(&cfg.Shake.Color),
+										Enabled:    Bind("vm.ShakeEnabled"),
+										ColumnSpan: 2,
+									},
+								},
+							},
+							GroupBox{
+								Title:  T("Spotlight"),
+								Layout: Grid{Columns: 2},
+								Children: []Widget{
+									CheckBox{
+										Text:             T("SpotlightEnabled"),
+										Checked:          Bind("SpotEnabled"),
+										OnCheckedChanged: update,
+										ColumnSpan:       2,
+									},
+									Label{Text: T("SpotlightShape")},
+									ComboBox{
+										Value:                 Bind("SpotShape"),
+										Model:                 spotlightShapeOptions,
+										BindingMember:         "Value",
+										DisplayMember:         "Name",
+										OnCurrentIndexChanged: update,
+										Enabled:               Bind("vm.SpotEnabled"),
+									},
+									Label{Text: T("SpotlightRadius")},
+									NumberEdit{
+										Value:          Bind("SpotRadius"),
+										MinValue:       20,
+										MaxValue:       2000,
+										OnValueChanged: update,
+										Decimals:       0,
+										Enabled:        Bind("vm.SpotEnabled"),
+									},
+									Label{Text: T("SpotlightWidth")},
+									NumberEdit{
+										Value:          Bind("SpotWidth"),
+										MinValue:       40,
+										MaxValue:       4000,
+										OnValueChanged: update,
+										Decimals:       0,
+										Enabled:        Bind("vm.SpotEnabled"),
+									},
+									Label{Text: T("SpotlightHeight")},
+									NumberEdit{
+										Value:          Bind("SpotHeight"),
+										MinValue:       40,
+										MaxValue:       4000,
+										OnValueChanged: update,
+										Decimals:       0,
+										Enabled:        Bind("vm.SpotEnabled"),
+									},
+									Label{Text: T("SpotlightFeather")},
+									NumberEdit{
+										Value:          Bind("SpotFeather"),
+										MinValue:       0,
+										MaxValue:       500,
+										OnValueChanged: update,
+										Decimals:       0,
+										Enabled:        Bind("vm.SpotEnabled"),
+									},
+									Label{Text: T("SpotlightDim")},
+									NumberEdit{
+										Value:          Bind("SpotDim"),
+										MinValue:       0.05,
+										MaxValue:       1,
+										OnValueChanged: update,
+										Decimals:       2,
+										Enabled:        Bind("vm.SpotEnabled"),
+									},
+								},
+							},
+							VSpacer{},
+						},
+					},
+					{
+						Title:  T("TabPresenter"),
+						Layout: VBox{},
+						Children: []Widget{
+							GroupBox{
+								Title:  T("Ink"),
+								Layout: Grid{Columns: 2},
+								Children: []Widget{
+									CheckBox{
+										Text:             T("InkEnabled"),
+										Checked:          Bind("InkEnabled"),
+										OnCheckedChanged: update,
+										ColumnSpan:       2,
+									},
+									Label{Text: T("InkHotkey")},
+									LineEdit{
+										Text:              Bind("InkHotkey"),
+										OnEditingFinished: update,
+										Enabled:           Bind("vm.InkEnabled"),
+									},
+									Label{Text: T("InkTool")},
+									ComboBox{
+										Value:                 Bind("InkTool"),
+										Model:                 inkToolOptions,
+										BindingMember:         "Value",
+										DisplayMember:         "Name",
+										OnCurrentIndexChanged: update,
+										Enabled:               Bind("vm.InkEnabled"),
+									},
+									Label{Text: T("InkWidth")},
+									NumberEdit{
+										Value:          Bind("InkWidth"),
+										MinValue:       1,
+										MaxValue:       40,
+										OnValueChanged: update,
+										Decimals:       0,
+										Enabled:        Bind("vm.InkEnabled"),
+									},
+									Label{Text: T("InkFadeAfter")},
+									NumberEdit{
+										Value:          Bind("InkFadeAfter"),
+										MinValue:       0,
+										MaxValue:       600,
+										OnValueChanged: update,
+										Decimals:       1,
+										Enabled:        Bind("vm.InkEnabled"),
+									},
+									PushButton{
+										Text:       T("InkColor"),
+										OnClicked:  pickHaloColor(&cfg.Ink.Color),
+										Enabled:    Bind("vm.InkEnabled"),
+										ColumnSpan: 2,
+									},
+									Label{
+										Text:       T("InkHint"),
+										ColumnSpan: 2,
+									},
+								},
+							},
+							GroupBox{
+								Title:  T("Laser"),
+								Layout: Grid{Columns: 2},
+								Children: []Widget{
+									CheckBox{
+										Text:             T("LaserEnabled"),
+										Checked:          Bind("LaserEnabled"),
+										OnCheckedChanged: update,
+										ColumnSpan:       2,
+									},
+									Label{Text: T("LaserWidth")},
+									NumberEdit{
+										Value:          Bind("LaserWidth"),
+										MinValue:       1,
+										MaxValue:       40,
+										OnValueChanged: update,
+										Decimals:       0,
+									},
+									Label{Text: T("LaserDot")},
+									NumberEdit{
+										Value:          Bind("LaserDot"),
+										MinValue:       0,
+										MaxValue:       40,
+										OnValueChanged: update,
+										Decimals:       0,
+									},
+									Label{Text: T("LaserAutoOff")},
+									NumberEdit{
+										Value:          Bind("LaserAutoOff"),
+										MinValue:       0,
+										MaxValue:       600,
+										OnValueChanged: update,
+										Decimals:       0,
+									},
+									CheckBox{
+										Text:             T("LaserHide"),
+										Checked:          Bind("LaserHide"),
+										OnCheckedChanged: update,
+										ColumnSpan:       2,
+									},
+									PushButton{
+										Text:       T("LaserColor"),
+										OnClicked:  pickHaloColor(&cfg.Laser.Color),
+										ColumnSpan: 2,
+									},
+								},
+							},
+							VSpacer{},
+						},
+					},
+					{
+						Title:  T("TabHotkeys"),
+						Layout: VBox{},
+						Children: []Widget{
+							GroupBox{
+								Title:  T("Hotkeys"),
+								Layout: Grid{Columns: 2},
+								Children: []Widget{
+									Label{Text: T("HotkeyPause")},
+									LineEdit{
+										Text:              Bind("HkPause"),
+										OnEditingFinished: update,
+									},
+									Label{Text: T("HotkeyRipples")},
+									LineEdit{
+										Text:              Bind("HkRipples"),
+										OnEditingFinished: update,
+									},
+									Label{Text: T("HotkeyPreset")},
+									LineEdit{
+										Text:              Bind("HkPreset"),
+										OnEditingFinished: update,
+									},
+									Label{Text: T("HotkeySpotlight")},
+									LineEdit{
+										Text:              Bind("HkSpotlight"),
+										OnEditingFinished: update,
+									},
+									Label{Text: T("HotkeyLaser")},
+									LineEdit{
+										Text:              Bind("HkLaser"),
+										OnEditingFinished: update,
+									},
+									Label{Text: T("HotkeyInkUndo")},
+									LineEdit{
+										Text:              Bind("HkInkUndo"),
+										OnEditingFinished: update,
+									},
+									Label{Text: T("HotkeyInkClear")},
+									LineEdit{
+										Text:              Bind("HkInkClear"),
+										OnEditingFinished: update,
+									},
+									Label{Text: T("HotkeyConfig")},
+									LineEdit{
+										Text:              Bind("HkConfig"),
+										OnEditingFinished: update,
+									},
+									Label{
+										Text:       T("HotkeysHint"),
+										ColumnSpan: 2,
+									},
+								},
+							},
+							VSpacer{},
+						},
+					},
+				},
+			},
+
+			Composite{
+				Layout: HBox{},
+				Children: []Widget{
+					HSpacer{},
+					PushButton{
+						Text: T("SaveClose"),
+						OnClicked: func() {
+							update()
+							// 按新的设置注册全局热键，无法解析、重复或被其他程序占用的热键不会生效，保存前提示
+							if errs := ReloadHotkeys(cfg); len(errs) > 0 {
+								msg := T("HotkeyErrors")
+								for _, err := range errs {
+									msg += "\n" + err.Error()
+								}
+								walk.MsgBox(mainWindow, T("Hotkeys"), msg, walk.MsgBoxIconWarning)
+							}
+							SaveConfig("config.json", cfg)
+							mainWindow.Close()
+						},
+					},
+				},
+			},
+		},
+	}).Create(); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// 控件创建完成后再填入当前按键的波纹样式
+	rippleEditor.load()
+	mainWindow.Run()
+}
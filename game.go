@@ -0,0 +1,232 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type Game struct {
+	traceManager   *TraceManager
+	keystrokes     *KeystrokeOverlay
+	config         *Config
+	input          InputSource
+	quitChan       chan struct{}
+	actionChan     chan OverlayAction
+	openConfigChan chan *Config    // 打开设置窗口，传入配置的副本
+	configChan     chan ConfigEdit // 设置窗口中的修改
+	overlay        OverlayBackend  // 脚本化输入时为 nil
+
+	screenWidth  int
+	screenHeight int
+
+	// 性能优化：空闲检测，记录最近一次有活动的时间
+	lastActive time.Time
+
+	// 手势识别 (单击、双击、长按、拖动)
+	gestures GestureRecognizer
+
+	// 在按键事件中匹配的全局热键，hotkeyCfg 为解析时的设置，设置改变后重新解析
+	hotkeys       HotkeyMatcher
+	hotkeyCfg     HotkeyConfig
+	hotkeyInk     InkConfig
+	hotkeysParsed bool
+
+	// 切换图层预设时保存的自定义图层，切换完所有预设后恢复
+	customLayers []TrailLayer
+
+	// 批注热键解析出的修饰键，inkHotkey 为解析时的设置，设置改变后重新解析
+	inkHotkey string
+	inkMods   Modifier
+	inkParsed bool
+	inkValid  bool
+}
+
+const (
+	// 无活动超过该时长后降低刷新率
+	idleSlowAfter = 1 * time.Second
+	idleDeepAfter = 5 * time.Second
+)
+
+func (g *Game) Update() error {
+	defer g.restoreCursorOnPanic()
+
+	// 检查退出信号
+	select {
+	case <-g.quitChan:
+		return ebiten.Termination
+	default:
+	}
+
+	// 应用设置窗口的修改，执行托盘菜单和热键请求的操作
+	// 配置只在游戏线程中修改，绘制时不会读到一半被替换的切片
+	for pending := true; pending; {
+		select {
+		case e := <-g.configChan:
+			e.Apply(g.config)
+		case a := <-g.actionChan:
+			g.applyAction(a)
+		default:
+			pending = false
+		}
+	}
+	publishTrayState(g.config)
+
+	// 只有按键显示、屏幕批注 (识别快捷键) 和没有系统热键时的全局热键需要读取键盘，
+	// 都不需要时不安装键盘钩子
+	if g.overlay != nil {
+		g.overlay.SetKeyboardCapture(g.config.Keystrokes.Enabled || g.config.Ink.Enabled || !nativeHotkeys)
+	}
+
+	in := g.input.Poll()
+	if !nativeHotkeys {
+		g.matchHotkeys(in.Keys)
+	}
+
+	// 暂停时只处理热键，不推进和绘制效果
+	if overlayPaused.Load() {
+		if g.overlay != nil {
+			g.overlay.SetCursorHidden(false)
+		}
+		g.lastActive = time.Time{}
+		ebiten.SetTPS(15)
+		return nil
+	}
+
+	isActive := g.step(in)
+
+	if g.overlay != nil {
+		g.overlay.SetCursorHidden(g.config.Laser.Enabled && g.config.Laser.HideCursor)
+	}
+
+	// 智能休眠逻辑
+	// 按时间而不是帧数判断，降低 TPS 后阈值不会被拉长
+	if isActive || g.lastActive.IsZero() {
+		g.lastActive = in.Time
+		ebiten.SetTPS(60) // 恢复高刷新率以保证流畅动画
+	} else {
+		idle := in.Time.Sub(g.lastActive)
+		if idle > idleDeepAfter { // 5秒无操作
+			ebiten.SetTPS(5) // 极低刷新率
+		} else if idle > idleSlowAfter { // 1 秒无活动
+			ebiten.SetTPS(15) // 降低刷新率以节省 CPU/GPU
+		}
+	}
+
+	return nil
+}
+
+// step 用一次输入采样推进轨迹和波纹
+// 不依赖窗口和平台 API，脚本化输入可以直接驱动
+func (g *Game) step(in InputState) bool {
+	// 按识别出的手势生成波纹和拖动图形
+	for _, ev := range g.gestures.Update(&g.config.Gestures, in) {
+		switch ev.Kind {
+		case GesturePress, GestureLongPress:
+			// 长按完成时在按下位置再扩散一次波纹
+			g.traceManager.AddRipple(ev.StartX, ev.StartY, ev.Button, ev.Mods)
+			if ev.Kind == GesturePress {
+				g.traceManager.AddClickParticles(ev.StartX, ev.StartY)
+			}
+		case GestureDoubleClick:
+			g.traceManager.AddDoubleRipple(ev.X, ev.Y, ev.Button, ev.Mods)
+			g.traceManager.AddClickParticles(ev.X, ev.Y)
+		case GestureDrag:
+			g.traceManager.AddDragMark(ev)
+		}
+	}
+	g.traceManager.SetGestures(&g.gestures)
+
+	pressed := false
+	for b := MouseButton(0); b < mouseButtonCount; b++ {
+		pressed = pressed || in.Pressed(b)
+	}
+	g.traceManager.SetHaloPressed(pressed)
+
+	// 开启批注时，按住批注热键 (只按住这些修饰键) 绘制笔画
+	// 期间按下了其他键或鼠标按键，说明是 Ctrl+Shift+T 这样的快捷键或 Ctrl+Shift+单击，取消这一笔
+	inkMods, ok := g.inkModifiers()
+	inking := g.config.Ink.Enabled && ok && in.Mods == inkMods
+	g.traceManager.SetInking(inking)
+	if inking && (len(in.Keys) > 0 || pressed) {
+		g.traceManager.CancelInkStroke()
+	}
+
+	if in.WheelX != 0 || in.WheelY != 0 {
+		g.traceManager.AddWheel(in.X, in.Y, in.WheelX, in.WheelY)
+	}
+
+	for _, ev := range in.Keys {
+		g.keystrokes.Add(ev)
+	}
+	keys := g.keystrokes.Update(in.Time, in.X, in.Y)
+
+	active := g.traceManager.Update(in.X, in.Y)
+	// 激光笔模式下光标静止超过 AutoOff 秒，自动回到普通轨迹
+	if g.traceManager.LaserIdle() {
+		g.applyAction(ActionToggleLaser)
+	}
+	return active || keys
+}
+
+// restoreCursorOnPanic 游戏循环崩溃时先恢复被激光笔模式隐藏的系统光标，再继续 panic
+// 系统光标的替换是全局的，进程退出后不会自动恢复
+func (g *Game) restoreCursorOnPanic() {
+	if r := recover(); r != nil {
+		if g.overlay != nil {
+			g.overlay.SetCursorHidden(false)
+		}
+		panic(r)
+	}
+}
+
+// inkModifiers 返回批注热键的修饰键，设置改变时重新解析，无效的设置返回 false
+func (g *Game) inkModifiers() (Modifier, bool) {
+	if !g.inkParsed || g.config.Ink.Hotkey != g.inkHotkey {
+		mods, err := ParseModifiers(g.config.Ink.Hotkey)
+		if err != nil {
+			log.Println("Ink hotkey:", err)
+		}
+		g.inkHotkey = g.config.Ink.Hotkey
+		g.inkMods, g.inkValid = mods, err == nil
+		g.inkParsed = true
+	}
+	return g.inkMods, g.inkValid
+}
+
+// matchHotkeys 在按键事件中查找全局热键并执行对应的操作
+// 用于没有系统全局热键的平台；热键设置改变时重新解析
+func (g *Game) matchHotkeys(keys []KeyEvent) {
+	if !g.hotkeysParsed || g.config.Hotkeys != g.hotkeyCfg || g.config.Ink != g.hotkeyInk {
+		bindings, errs := ParseHotkeys(g.config)
+		for _, err := range errs {
+			log.Println("Hotkey:", err)
+		}
+		g.hotkeys = bindings
+		g.hotkeyCfg = g.config.Hotkeys
+		g.hotkeyInk = g.config.Ink
+		g.hotkeysParsed = true
+	}
+	for _, ev := range keys {
+		if a, ok := g.hotkeys.Match(ev); ok {
+			g.applyAction(a)
+		}
+	}
+}
+
+func (g *Game) Draw(screen *ebiten.Image) {
+	defer g.restoreCursorOnPanic()
+
+	if overlayPaused.Load() {
+		return
+	}
+	// 绘制轨迹
+	g.traceManager.Draw(screen)
+	// 按键气泡绘制在轨迹之上
+	g.keystrokes.Draw(screen)
+}
+
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return g.screenWidth, g.screenHeight
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newScriptedGame 创建由 in 驱动的 Game，in 同时作为轨迹的时钟，不需要窗口和平台 API
+func newScriptedGame(cfg *Config, in *ScriptedInput) *Game {
+	g := &Game{
+		traceManager: NewTraceManager(cfg),
+		keystrokes:   NewKeystrokeOverlay(cfg),
+		config:       cfg,
+		input:        in,
+	}
+	g.traceManager.SetClock(in)
+	return g
+}
+
+// runScript 回放 in 中剩余的所有帧，返回最后一帧 step 的结果
+func runScript(g *Game, in *ScriptedInput) bool {
+	active := false
+	for !in.Done() {
+		active = g.step(in.Poll())
+	}
+	return active
+}
+
+func TestStepTrail(t *testing.T) {
+	cfg := DefaultConfig()
+	in := NewScriptedInput()
+	in.MoveTo(100, 0, 10)
+	g := newScriptedGame(cfg, in)
+
+	if !runScript(g, in) {
+		t.Fatal("step reported idle while moving")
+	}
+	// 每帧移动 10 像素，大于采样间距，每帧采样一个点
+	if n := len(g.traceManager.points); n != 10 {
+		t.Fatalf("trail has %d points, want 10", n)
+	}
+	if head := g.traceManager.points[9]; head.X != 100 || head.Y != 0 {
+		t.Errorf("trail head at (%v, %v), want (100, 0)", head.X, head.Y)
+	}
+
+	// 停下后轨迹在 TailLifetime 内消失
+	in.Wait(30)
+	if runScript(g, in) {
+		t.Error("step reported active after the trail expired")
+	}
+	if n := len(g.traceManager.points); n != 0 {
+		t.Errorf("trail has %d points after expiring, want 0", n)
+	}
+}
+
+func TestStepRipples(t *testing.T) {
+	cfg := DefaultConfig()
+	in := NewScriptedInput(InputState{X: 50, Y: 40})
+	in.Click(MouseButtonLeft)
+	g := newScriptedGame(cfg, in)
+	runScript(g, in)
+
+	ripples := g.traceManager.ripples
+	if len(ripples) != 1 {
+		t.Fatalf("%d ripples after a click, want 1", len(ripples))
+	}
+	if r := ripples[0]; r.X != 50 || r.Y != 40 || r.Rings != 1 {
+		t.Errorf("ripple = (%v, %v) with %d rings, want (50, 40) with 1", r.X, r.Y, r.Rings)
+	}
+
+	// 双击的第二次按下产生双圆环
+	in.Click(MouseButtonLeft)
+	runScript(g, in)
+	ripples = g.traceManager.ripples
+	if len(ripples) != 2 || ripples[1].Rings != 2 {
+		t.Fatalf("ripples after a double click = %+v, want a second ripple with 2 rings", ripples)
+	}
+
+	// 关闭波纹后点击不再产生波纹
+	cfg.IsRipple = false
+	in.Wait(30)
+	in.Click(MouseButtonRight)
+	runScript(g, in)
+	if n := len(g.traceManager.ripples); n != 0 {
+		t.Errorf("%d ripples with ripples disabled, want 0", n)
+	}
+}
+
+func TestStepKeystrokes(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Keystrokes.Enabled = true
+	in := NewScriptedInput()
+	in.Key("S", ModCtrl)
+	in.Key("S", ModCtrl)
+	in.Hold(0)
+	in.Type("H", 0)
+	in.Type("I", ModShift)
+	in.Key("Enter", 0)
+	g := newScriptedGame(cfg, in)
+	runScript(g, in)
+
+	want := []struct {
+		label string
+		count int
+	}{
+		{"Ctrl+S", 2},
+		{"hI", 1},
+		{"Enter", 1},
+	}
+	bubbles := g.keystrokes.bubbles
+	if len(bubbles) != len(want) {
+		t.Fatalf("%d bubbles, want %d", len(bubbles), len(want))
+	}
+	for i, w := range want {
+		if b := bubbles[i]; b.label != w.label || b.count != w.count {
+			t.Errorf("bubble %d = %q x%d, want %q x%d", i, b.label, b.count, w.label, w.count)
+		}
+	}
+
+	// 只显示组合键时，普通输入不产生气泡
+	cfg.Keystrokes.ShowTyping = false
+	in.Wait(120)
+	in.Type("A", 0)
+	runScript(g, in)
+	if n := len(g.keystrokes.bubbles); n != 0 {
+		t.Errorf("%d bubbles for typing with show_typing off, want 0", n)
+	}
+}
+
+func TestStepInk(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Ink.Enabled = true
+	in := NewScriptedInput(InputState{X: 10, Y: 10})
+	in.Hold(ModCtrl | ModShift)
+	in.MoveTo(60, 10, 5)
+	in.Hold(0)
+	g := newScriptedGame(cfg, in)
+	runScript(g, in)
+	if n := len(g.traceManager.ink.strokes); n != 1 {
+		t.Fatalf("%d strokes after drawing with the ink hotkey, want 1", n)
+	}
+
+	// 按住 Ctrl+Shift 单击或拖动是普通的鼠标操作，不留下笔画
+	in.Hold(ModCtrl | ModShift)
+	in.Click(MouseButtonLeft)
+	in.Press(MouseButtonLeft)
+	in.MoveTo(100, 50, 5)
+	in.Release(MouseButtonLeft)
+	in.MoveTo(120, 50, 3)
+	in.Hold(0)
+	runScript(g, in)
+	if n := len(g.traceManager.ink.strokes); n != 1 {
+		t.Errorf("%d strokes after Ctrl+Shift+click and drag, want 1", n)
+	}
+
+	// 关闭批注后按住热键不绘制
+	g.traceManager.ClearInk()
+	cfg.Ink.Enabled = false
+	in.Hold(ModCtrl | ModShift)
+	in.MoveTo(10, 10, 5)
+	in.Hold(0)
+	runScript(g, in)
+	if n := len(g.traceManager.ink.strokes); n != 0 {
+		t.Errorf("%d strokes with ink disabled, want 0", n)
+	}
+}
+
+func TestStepLaserAutoOff(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Laser.Enabled = true
+	cfg.Laser.AutoOff = 0.5
+	in := NewScriptedInput()
+	in.MoveTo(100, 0, 10)
+	in.Wait(25)
+	g := newScriptedGame(cfg, in)
+	runScript(g, in)
+	if !cfg.Laser.Enabled {
+		t.Fatal("laser turned off before the cursor was idle for auto_off")
+	}
+
+	// 静止超过 AutoOff 秒后由 Game 关闭激光笔模式
+	in.Wait(10)
+	runScript(g, in)
+	if cfg.Laser.Enabled {
+		t.Error("laser still on after the cursor was idle for auto_off")
+	}
+}
+
+func TestCyclePresetKeepsCustomLayers(t *testing.T) {
+	cfg := DefaultConfig()
+	custom := []TrailLayer{{Enabled: true, Length: 7, WidthScale: 3, Opacity: 0.5}}
+	cfg.Layers = custom
+	g := newScriptedGame(cfg, NewScriptedInput())
+
+	// 依次切换所有预设，之后回到自定义图层
+	for _, name := range trailLayerPresets {
+		g.applyAction(ActionCyclePreset)
+		if got, ok := trailLayerPresetName(cfg.Layers); !ok || got != name {
+			t.Fatalf("layers after cycling = %+v, want preset %q", cfg.Layers, name)
+		}
+	}
+	g.applyAction(ActionCyclePreset)
+	if !reflect.DeepEqual(cfg.Layers, custom) {
+		t.Fatalf("layers after cycling through the presets = %+v, want the custom layers", cfg.Layers)
+	}
+
+	// 再切换一轮仍然保留自定义图层
+	for range len(trailLayerPresets) + 1 {
+		g.applyAction(ActionCyclePreset)
+	}
+	if !reflect.DeepEqual(cfg.Layers, custom) {
+		t.Errorf("layers after a second cycle = %+v, want the custom layers", cfg.Layers)
+	}
+
+	// 没有自定义图层时只在预设之间切换
+	cfg.Layers = nil
+	g.customLayers = nil
+	for _, name := range append(trailLayerPresets[1:], trailLayerPresets[0]) {
+		g.applyAction(ActionCyclePreset)
+		if got, ok := trailLayerPresetName(cfg.Layers); !ok || got != name {
+			t.Fatalf("layers after cycling = %+v, want preset %q", cfg.Layers, name)
+		}
+	}
+}
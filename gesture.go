@@ -0,0 +1,345 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// 拖动图形
+const (
+	DragShapeLine = "line" // 从按下点到松开点的线段
+	DragShapeRect = "rect" // 以按下点和松开点为对角的矩形框
+)
+
+const (
+	// 按住超过长按时长的该比例后才显示进度环，避免每次普通点击都闪一下
+	holdShowAfter = 0.25
+	// 长按进度环的半径和宽度 (像素)
+	holdRadius    = 18.0
+	holdThickness = 3.0
+	// 双击波纹两个圆环之间的间距 (以圆环宽度为单位)
+	doubleRingGap = 2.5
+)
+
+// GestureConfig 手势识别参数
+type GestureConfig struct {
+	Enabled             bool    `json:"enabled"`
+	DoubleClickTime     float64 `json:"double_click_time"`     // 两次按下的最大间隔 (秒)
+	DoubleClickDistance float64 `json:"double_click_distance"` // 两次按下的最大距离 (像素)
+	LongPressTime       float64 `json:"long_press_time"`       // 按住不动达到该时长 (秒) 视为长按
+	DragThreshold       float64 `json:"drag_threshold"`        // 按住移动超过该距离 (像素) 视为拖动
+	DragShape           string  `json:"drag_shape"`            // "line", "rect"
+	MarkDuration        float64 `json:"mark_duration"`         // 拖动图形松开后的淡出时间 (秒)
+}
+
+func defaultGestureConfig() GestureConfig {
+	return GestureConfig{
+		Enabled:             true,
+		DoubleClickTime:     0.4,
+		DoubleClickDistance: 6,
+		LongPressTime:       0.6,
+		DragThreshold:       8,
+		DragShape:           DragShapeRect,
+		MarkDuration:        0.6,
+	}
+}
+
+// GestureKind 手势类型
+type GestureKind int
+
+const (
+	GesturePress       GestureKind = iota // 按下 (双击的第二次按下除外)
+	GestureClick                          // 短按后在原地松开
+	GestureDoubleClick                    // 双击的第二次按下
+	GestureLongPress                      // 按住不动达到长按时长
+	GestureDrag                           // 拖动后松开
+)
+
+// GestureEvent 识别出的手势
+type GestureEvent struct {
+	Kind           GestureKind
+	Button         MouseButton
+	X, Y           int      // 事件发生的位置
+	StartX, StartY int      // 对应的按下位置
+	Mods           Modifier // 按下时按住的修饰键
+}
+
+// buttonGesture 单个按键的识别状态
+type buttonGesture struct {
+	down           bool
+	pressTime      time.Time
+	pressX, pressY int
+	pressMods      Modifier
+	dragging       bool
+	longPressed    bool
+	double         bool    // 本次按下是双击的第二次
+	progress       float64 // 长按进度 0-1
+
+	// 上一次完成的单击，用于识别双击
+	lastClick    time.Time
+	lastX, lastY int
+}
+
+// GestureRecognizer 从按键状态序列中识别单击、双击、长按和拖动
+// 只依赖 InputState 的时间戳，脚本化输入可以直接驱动
+type GestureRecognizer struct {
+	buttons [mouseButtonCount]buttonGesture
+	x, y    int
+	events  []GestureEvent
+}
+
+// Update 处理一次输入采样，返回本次识别出的手势
+// 返回的切片在下次调用时复用。未启用手势时只产生 GesturePress
+func (r *GestureRecognizer) Update(cfg *GestureConfig, in InputState) []GestureEvent {
+	r.events = r.events[:0]
+	r.x, r.y = in.X, in.Y
+
+	for b := MouseButton(0); b < mouseButtonCount; b++ {
+		s := &r.buttons[b]
+		pressed := in.Pressed(b)
+
+		switch {
+		case pressed && !s.down:
+			kind := GesturePress
+			if cfg.Enabled && !s.lastClick.IsZero() &&
+				in.Time.Sub(s.lastClick).Seconds() <= cfg.DoubleClickTime &&
+				distance(in.X, in.Y, s.lastX, s.lastY) <= cfg.DoubleClickDistance {
+				kind = GestureDoubleClick
+			}
+			*s = buttonGesture{
+				down:      true,
+				pressTime: in.Time,
+				pressX:    in.X,
+				pressY:    in.Y,
+				pressMods: in.Mods,
+				double:    kind == GestureDoubleClick,
+			}
+			r.emit(kind, b, s)
+
+		case pressed && s.down:
+			if !cfg.Enabled || s.longPressed {
+				break
+			}
+			if !s.dragging && distance(in.X, in.Y, s.pressX, s.pressY) > cfg.DragThreshold {
+				s.dragging = true
+				s.progress = 0
+			}
+			if !s.dragging && cfg.LongPressTime > 0 {
+				s.progress = math.Min(1, in.Time.Sub(s.pressTime).Seconds()/cfg.LongPressTime)
+				if s.progress >= 1 {
+					s.longPressed = true
+					r.emit(GestureLongPress, b, s)
+				}
+			}
+
+		case !pressed && s.down:
+			s.down = false
+			s.progress = 0
+			switch {
+			case !cfg.Enabled:
+			case s.dragging:
+				r.emit(GestureDrag, b, s)
+			case s.longPressed:
+			default:
+				r.emit(GestureClick, b, s)
+				// 双击之后的第三次按下重新开始计数
+				if !s.double {
+					s.lastClick = s.pressTime
+					s.lastX, s.lastY = s.pressX, s.pressY
+				}
+			}
+		}
+	}
+	return r.events
+}
+
+func (r *GestureRecognizer) emit(kind GestureKind, b MouseButton, s *buttonGesture) {
+	r.events = append(r.events, GestureEvent{
+		Kind:   kind,
+		Button: b,
+		X:      r.x,
+		Y:      r.y,
+		StartX: s.pressX,
+		StartY: s.pressY,
+		Mods:   s.pressMods,
+	})
+}
+
+// Holding 返回按键正在进行的长按的位置和进度 (0-1)
+func (r *GestureRecognizer) Holding(b MouseButton) (x, y int, progress float64, ok bool) {
+	s := &r.buttons[b]
+	if !s.down || s.dragging || s.longPressed || s.progress <= 0 {
+		return 0, 0, 0, false
+	}
+	return s.pressX, s.pressY, s.progress, true
+}
+
+// Dragging 返回按键正在进行的拖动的起点和当前位置
+func (r *GestureRecognizer) Dragging(b MouseButton) (x0, y0, x1, y1 int, ok bool) {
+	s := &r.buttons[b]
+	if !s.down || !s.dragging {
+		return 0, 0, 0, 0, false
+	}
+	return s.pressX, s.pressY, r.x, r.y, true
+}
+
+func distance(x0, y0, x1, y1 int) float64 {
+	return math.Hypot(float64(x1-x0), float64(y1-y0))
+}
+
+// holdMark 长按进度环
+type holdMark struct {
+	X, Y     float64
+	Progress float64
+	Color    [4]uint8
+}
+
+// dragMark 拖动图形，Life 为 1 时表示仍在拖动
+type dragMark struct {
+	X0, Y0, X1, Y1 float64
+	Life           float64
+	Color          [4]uint8
+	Width          float64
+}
+
+// SetGestures 根据识别器的当前状态更新长按进度环和正在拖动的图形，每帧调用
+func (tm *TraceManager) SetGestures(r *GestureRecognizer) {
+	tm.holds = tm.holds[:0]
+	tm.activeDrags = tm.activeDrags[:0]
+	if !tm.config.Gestures.Enabled {
+		return
+	}
+
+	for b := MouseButton(0); b < mouseButtonCount; b++ {
+		style := tm.config.ButtonRipples.For(b)
+		if !style.Enabled {
+			continue
+		}
+		if x, y, p, ok := r.Holding(b); ok && p >= holdShowAfter {
+			tm.holds = append(tm.holds, holdMark{
+				X:        float64(x),
+				Y:        float64(y),
+				Progress: (p - holdShowAfter) / (1 - holdShowAfter),
+				Color:    style.Color,
+			})
+		}
+		if x0, y0, x1, y1, ok := r.Dragging(b); ok {
+			tm.activeDrags = append(tm.activeDrags, tm.newDragMark(b, x0, y0, x1, y1))
+		}
+	}
+}
+
+// AddDragMark 在拖动结束时留下逐渐淡出的拖动图形
+func (tm *TraceManager) AddDragMark(ev GestureEvent) {
+	if !tm.config.Gestures.Enabled || !tm.config.ButtonRipples.For(ev.Button).Enabled {
+		return
+	}
+	tm.drags = append(tm.drags, tm.newDragMark(ev.Button, ev.StartX, ev.StartY, ev.X, ev.Y))
+}
+
+func (tm *TraceManager) newDragMark(b MouseButton, x0, y0, x1, y1 int) dragMark {
+	style := tm.config.ButtonRipples.For(b)
+	width := style.Width
+	if width <= 0 {
+		width = tm.config.RippleWidth
+	}
+	return dragMark{
+		X0: float64(x0), Y0: float64(y0),
+		X1: float64(x1), Y1: float64(y1),
+		Life:  1,
+		Color: style.Color,
+		Width: math.Max(1, width/2),
+	}
+}
+
+// updateGestureMarks 淡出已结束的拖动图形，返回是否还有需要绘制的手势图形
+func (tm *TraceManager) updateGestureMarks(dt float64) bool {
+	decay := 1.0
+	if tm.config.Gestures.MarkDuration > 0 {
+		decay = dt / tm.config.Gestures.MarkDuration
+	}
+	active := 0
+	for i := range tm.drags {
+		tm.drags[i].Life -= decay
+		if tm.drags[i].Life > 0 {
+			tm.drags[active] = tm.drags[i]
+			active++
+		}
+	}
+	tm.drags = tm.drags[:active]
+
+	return len(tm.holds) > 0 || len(tm.activeDrags) > 0 || len(tm.drags) > 0
+}
+
+// buildGestureGeometry 生成长按进度环和拖动图形的三角形
+func (tm *TraceManager) buildGestureGeometry() {
+	for _, h := range tm.holds {
+		// 淡色底环加上按进度填充的圆弧，从 12 点方向顺时针增长
+		tm.addArc(h.X, h.Y, holdRadius, holdThickness, -math.Pi/2, 2*math.Pi, tm.styleColor(h.Color, 0.25))
+		tm.addArc(h.X, h.Y, holdRadius, holdThickness, -math.Pi/2, 2*math.Pi*h.Progress, tm.styleColor(h.Color, 1))
+	}
+
+	for _, d := range tm.activeDrags {
+		tm.addDragMark(d)
+	}
+	for _, d := range tm.drags {
+		tm.addDragMark(d)
+	}
+}
+
+func (tm *TraceManager) addDragMark(d dragMark) {
+	c := tm.styleColor(d.Color, d.Life)
+	if tm.config.Gestures.DragShape == DragShapeLine {
+		tm.addSegment(d.X0, d.Y0, d.X1, d.Y1, d.Width, c)
+		return
+	}
+	// 每条边两端各延长半个线宽，让四个角闭合
+	h := d.Width / 2
+	x0, x1 := math.Min(d.X0, d.X1), math.Max(d.X0, d.X1)
+	y0, y1 := math.Min(d.Y0, d.Y1), math.Max(d.Y0, d.Y1)
+	tm.addSegment(x0-h, y0, x1+h, y0, d.Width, c)
+	tm.addSegment(x0-h, y1, x1+h, y1, d.Width, c)
+	tm.addSegment(x0, y0, x0, y1, d.Width, c)
+	tm.addSegment(x1, y0, x1, y1, d.Width, c)
+}
+
+// addArc 添加一段圆弧环，从 start 开始扫过 sweep 弧度
+func (tm *TraceManager) addArc(x, y, radius, thickness, start, sweep float64, c vertexColor) {
+	const segmentsPerTurn = 32
+	segments := int(math.Ceil(math.Abs(sweep) / (2 * math.Pi) * segmentsPerTurn))
+	if segments == 0 || c.A <= 0 {
+		return
+	}
+
+	startIndex := uint32(len(tm.vertices))
+	for i := 0; i <= segments; i++ {
+		sin, cos := math.Sincos(start + sweep*float64(i)/float64(segments))
+		tm.vertices = append(tm.vertices,
+			ebitenVertex(x+radius*cos, y+radius*sin, c),
+			ebitenVertex(x+(radius+thickness)*cos, y+(radius+thickness)*sin, c),
+		)
+	}
+	for i := 0; i < segments; i++ {
+		idx := startIndex + uint32(i*2)
+		tm.indices = append(tm.indices, idx, idx+1, idx+2, idx+1, idx+3, idx+2)
+	}
+}
+
+// addSegment 添加一条宽度为 width 的线段
+func (tm *TraceManager) addSegment(x0, y0, x1, y1, width float64, c vertexColor) {
+	l := math.Hypot(x1-x0, y1-y0)
+	if l == 0 || c.A <= 0 {
+		return
+	}
+	nx := -(y1 - y0) / l * width / 2
+	ny := (x1 - x0) / l * width / 2
+
+	idx := uint32(len(tm.vertices))
+	tm.vertices = append(tm.vertices,
+		ebitenVertex(x0+nx, y0+ny, c),
+		ebitenVertex(x0-nx, y0-ny, c),
+		ebitenVertex(x1+nx, y1+ny, c),
+		ebitenVertex(x1-nx, y1-ny, c),
+	)
+	tm.indices = append(tm.indices, idx, idx+1, idx+2, idx+1, idx+3, idx+2)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+// gestureFrame 左键在按下后 ms 毫秒时的状态
+type gestureFrame struct {
+	ms   int
+	x, y int
+	down bool
+}
+
+// gestureInput 把 frames 转换为按时间戳回放的输入
+func gestureInput(frames []gestureFrame) *ScriptedInput {
+	in := NewScriptedInput()
+	for _, f := range frames {
+		s := InputState{X: f.x, Y: f.y, Time: in.Start.Add(time.Duration(f.ms) * time.Millisecond)}
+		s.Buttons[MouseButtonLeft] = f.down
+		in.Append(s)
+	}
+	return in
+}
+
+func TestGestureRecognizer(t *testing.T) {
+	const (
+		press  = GesturePress
+		click  = GestureClick
+		double = GestureDoubleClick
+		long   = GestureLongPress
+		drag   = GestureDrag
+	)
+	tests := []struct {
+		name   string
+		setup  func(cfg *GestureConfig)
+		frames []gestureFrame
+		want   []GestureKind
+	}{
+		{
+			name:   "click",
+			frames: []gestureFrame{{0, 0, 0, true}, {100, 0, 0, false}},
+			want:   []GestureKind{press, click},
+		},
+		{
+			// 第二次按下正好在 DoubleClickTime 时仍算双击
+			name:   "double click at the time limit",
+			frames: []gestureFrame{{0, 0, 0, true}, {50, 0, 0, false}, {400, 0, 0, true}, {450, 0, 0, false}},
+			want:   []GestureKind{press, click, double, click},
+		},
+		{
+			name:   "second press after the time limit",
+			frames: []gestureFrame{{0, 0, 0, true}, {50, 0, 0, false}, {401, 0, 0, true}, {450, 0, 0, false}},
+			want:   []GestureKind{press, click, press, click},
+		},
+		{
+			name:   "double click at the distance limit",
+			frames: []gestureFrame{{0, 0, 0, true}, {50, 0, 0, false}, {200, 6, 0, true}, {250, 6, 0, false}},
+			want:   []GestureKind{press, click, double, click},
+		},
+		{
+			name:   "second press beyond the distance limit",
+			frames: []gestureFrame{{0, 0, 0, true}, {50, 0, 0, false}, {200, 7, 0, true}, {250, 7, 0, false}},
+			want:   []GestureKind{press, click, press, click},
+		},
+		{
+			// 双击之后的第三次按下重新开始计数
+			name: "triple click",
+			frames: []gestureFrame{
+				{0, 0, 0, true}, {50, 0, 0, false},
+				{100, 0, 0, true}, {150, 0, 0, false},
+				{200, 0, 0, true}, {250, 0, 0, false},
+			},
+			want: []GestureKind{press, click, double, click, press, click},
+		},
+		{
+			name:   "released just before the long press time",
+			frames: []gestureFrame{{0, 0, 0, true}, {300, 0, 0, true}, {599, 0, 0, true}, {650, 0, 0, false}},
+			want:   []GestureKind{press, click},
+		},
+		{
+			// 长按完成后松开不再产生单击
+			name:   "long press",
+			frames: []gestureFrame{{0, 0, 0, true}, {300, 0, 0, true}, {600, 0, 0, true}, {700, 0, 0, true}, {800, 0, 0, false}},
+			want:   []GestureKind{press, long},
+		},
+		{
+			name:   "move within the drag threshold",
+			frames: []gestureFrame{{0, 0, 0, true}, {100, 8, 0, true}, {150, 8, 0, false}},
+			want:   []GestureKind{press, click},
+		},
+		{
+			name:   "move beyond the drag threshold",
+			frames: []gestureFrame{{0, 0, 0, true}, {100, 9, 0, true}, {150, 9, 0, false}},
+			want:   []GestureKind{press, drag},
+		},
+		{
+			// 开始拖动后按住超过长按时长不再触发长按
+			name:   "drag held past the long press time",
+			frames: []gestureFrame{{0, 0, 0, true}, {300, 20, 0, true}, {700, 20, 0, true}, {900, 40, 0, true}, {950, 40, 0, false}},
+			want:   []GestureKind{press, drag},
+		},
+		{
+			// 长按完成后再移动不变成拖动
+			name:   "move after a long press",
+			frames: []gestureFrame{{0, 0, 0, true}, {600, 0, 0, true}, {700, 30, 0, true}, {750, 30, 0, false}},
+			want:   []GestureKind{press, long},
+		},
+		{
+			name:   "disabled",
+			setup:  func(cfg *GestureConfig) { cfg.Enabled = false },
+			frames: []gestureFrame{{0, 0, 0, true}, {50, 0, 0, false}, {100, 0, 0, true}, {700, 30, 0, true}, {750, 30, 0, false}},
+			want:   []GestureKind{press, press},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultGestureConfig()
+			if tt.setup != nil {
+				tt.setup(&cfg)
+			}
+			in := gestureInput(tt.frames)
+			var r GestureRecognizer
+			var got []GestureKind
+			for !in.Done() {
+				for _, ev := range r.Update(&cfg, in.Poll()) {
+					got = append(got, ev.Kind)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("gestures = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGestureDragPosition(t *testing.T) {
+	cfg := defaultGestureConfig()
+	in := gestureInput([]gestureFrame{{0, 10, 20, true}, {100, 40, 60, true}, {150, 50, 70, false}})
+	var r GestureRecognizer
+
+	r.Update(&cfg, in.Poll())
+	r.Update(&cfg, in.Poll())
+	if x0, y0, x1, y1, ok := r.Dragging(MouseButtonLeft); !ok || x0 != 10 || y0 != 20 || x1 != 40 || y1 != 60 {
+		t.Errorf("Dragging = (%d, %d)-(%d, %d) %v, want (10, 20)-(40, 60) true", x0, y0, x1, y1, ok)
+	}
+	evs := r.Update(&cfg, in.Poll())
+	if len(evs) != 1 || evs[0].Kind != GestureDrag {
+		t.Fatalf("events on release = %+v, want a drag", evs)
+	}
+	if ev := evs[0]; ev.StartX != 10 || ev.StartY != 20 || ev.X != 50 || ev.Y != 70 {
+		t.Errorf("drag = (%d, %d)-(%d, %d), want (10, 20)-(50, 70)", ev.StartX, ev.StartY, ev.X, ev.Y)
+	}
+}
+
+func TestGestureLongPressProgress(t *testing.T) {
+	cfg := defaultGestureConfig()
+	tests := []struct {
+		ms       int
+		progress float64
+		holding  bool
+	}{
+		{0, 0, false}, // 刚按下还没有进度
+		{150, 0.25, true},
+		{300, 0.5, true},
+		{450, 0.75, true},
+		{600, 0, false}, // 完成后不再显示进度
+		{700, 0, false},
+	}
+	frames := make([]gestureFrame, len(tests))
+	for i, tt := range tests {
+		frames[i] = gestureFrame{tt.ms, 30, 40, true}
+	}
+	in := gestureInput(frames)
+	var r GestureRecognizer
+	for _, tt := range tests {
+		evs := r.Update(&cfg, in.Poll())
+		x, y, p, ok := r.Holding(MouseButtonLeft)
+		if ok != tt.holding || math.Abs(p-tt.progress) > 1e-9 {
+			t.Errorf("%dms: Holding = %v %v, want %v %v", tt.ms, p, ok, tt.progress, tt.holding)
+		}
+		if ok && (x != 30 || y != 40) {
+			t.Errorf("%dms: holding at (%d, %d), want (30, 40)", tt.ms, x, y)
+		}
+		// 长按在进度到达 1 的那一帧完成，位置为按下的位置
+		if tt.ms == 600 {
+			if len(evs) != 1 || evs[0].Kind != GestureLongPress || evs[0].StartX != 30 || evs[0].StartY != 40 {
+				t.Errorf("events at the long press time = %+v, want a long press at (30, 40)", evs)
+			}
+		} else if tt.ms > 0 && len(evs) != 0 {
+			t.Errorf("%dms: unexpected events %+v", tt.ms, evs)
+		}
+	}
+}
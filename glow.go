@@ -0,0 +1,186 @@
+package main
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// 辉光在缩小的离屏图像上模糊，边长缩小的倍数
+	glowDownsample = 4
+	// 单方向模糊的采样数 (奇数)
+	glowTaps = 9
+)
+
+// GlowConfig 辉光设置
+// 把轨迹的模糊副本叠加在清晰的轨迹下方
+type GlowConfig struct {
+	Enabled   bool    `json:"enabled"`
+	Radius    float64 `json:"radius"`    // 模糊半径 (像素)
+	Intensity float64 `json:"intensity"` // 亮度倍数
+}
+
+func defaultGlowConfig() GlowConfig {
+	return GlowConfig{
+		Enabled:   false,
+		Radius:    16,
+		Intensity: 1.5,
+	}
+}
+
+// glowPass 辉光渲染使用的离屏图像和缓存
+// 只在开启辉光且有内容需要绘制时才分配，没有轨迹时不产生任何开销
+type glowPass struct {
+	small, tmp *ebiten.Image   // 缩小后的几何图像和模糊中间结果
+	scaled     []ebiten.Vertex // 缩小后的顶点，每帧复用
+	weights    [glowTaps]float32
+
+	// 软件渲染路径的缓存
+	soft    *image.RGBA
+	softBuf []float32
+}
+
+// glowSigma 高斯模糊的标准差，Radius 约为两个标准差
+func glowSigma(cfg *GlowConfig) float64 {
+	return math.Max(0.5, cfg.Radius/2)
+}
+
+// gaussianWeights 计算归一化的高斯权重，第 i 个采样的偏移为 (i - glowTaps/2) 个 sigma/2
+func (g *glowPass) gaussianWeights() {
+	sum := float32(0)
+	for i := range g.weights {
+		x := float64(i-glowTaps/2) / 2
+		g.weights[i] = float32(math.Exp(-x * x / 2))
+		sum += g.weights[i]
+	}
+	for i := range g.weights {
+		g.weights[i] /= sum
+	}
+}
+
+// Release 释放离屏图像，关闭辉光时调用
+func (g *glowPass) Release() {
+	if g.small != nil {
+		g.small.Deallocate()
+		g.tmp.Deallocate()
+		g.small, g.tmp = nil, nil
+	}
+	g.soft, g.softBuf = nil, nil
+}
+
+// Draw 把 vertices 的模糊副本以叠加混合绘制到 screen，draw 负责把 (缩小后的) 顶点画到离屏图像
+func (g *glowPass) Draw(screen *ebiten.Image, cfg *GlowConfig, vertices []ebiten.Vertex, draw func(dst *ebiten.Image, vertices []ebiten.Vertex)) {
+	b := screen.Bounds()
+	w := (b.Dx() + glowDownsample - 1) / glowDownsample
+	h := (b.Dy() + glowDownsample - 1) / glowDownsample
+	if g.small == nil || g.small.Bounds().Dx() != w || g.small.Bounds().Dy() != h {
+		g.Release()
+		g.small = ebiten.NewImage(w, h)
+		g.tmp = ebiten.NewImage(w, h)
+	}
+
+	// 1. 把几何缩小画到离屏图像
+	g.scaled = append(g.scaled[:0], vertices...)
+	for i := range g.scaled {
+		g.scaled[i].DstX /= glowDownsample
+		g.scaled[i].DstY /= glowDownsample
+	}
+	g.small.Clear()
+	draw(g.small, g.scaled)
+
+	// 2. 可分离高斯模糊：先水平再垂直
+	g.gaussianWeights()
+	step := glowSigma(cfg) / 2 / glowDownsample
+	g.blur(g.tmp, g.small, step, 0)
+	g.blur(g.small, g.tmp, 0, step)
+
+	// 3. 放大并叠加到屏幕
+	op := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear, Blend: ebiten.BlendLighter}
+	op.GeoM.Scale(glowDownsample, glowDownsample)
+	k := float32(cfg.Intensity)
+	op.ColorScale.Scale(k, k, k, k)
+	screen.DrawImage(g.small, op)
+}
+
+// blur 把 src 沿 (dx, dy) 方向做一次加权采样累加到 dst
+func (g *glowPass) blur(dst, src *ebiten.Image, dx, dy float64) {
+	dst.Clear()
+	for i, wt := range g.weights {
+		off := float64(i - glowTaps/2)
+		op := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear, Blend: ebiten.BlendLighter}
+		op.GeoM.Translate(off*dx, off*dy)
+		op.ColorScale.Scale(wt, wt, wt, wt)
+		dst.DrawImage(src, op)
+	}
+}
+
+// DrawRGBA 软件渲染路径的辉光：用 rasterize 全分辨率光栅化后做三次盒式模糊 (近似高斯)，再叠加到 dst
+func (g *glowPass) DrawRGBA(dst *image.RGBA, cfg *GlowConfig, rasterize func(dst *image.RGBA)) {
+	b := dst.Bounds()
+	if g.soft == nil || g.soft.Bounds() != b {
+		g.soft = image.NewRGBA(b)
+	}
+	clear(g.soft.Pix)
+	rasterize(g.soft)
+
+	w, h := b.Dx(), b.Dy()
+	if cap(g.softBuf) < w*h*4 {
+		g.softBuf = make([]float32, w*h*4)
+	}
+	buf := g.softBuf[:w*h*4]
+	for i, v := range g.soft.Pix[:len(buf)] {
+		buf[i] = float32(v)
+	}
+
+	// 三次宽度为 2r+1 的盒式模糊的方差为 r(r+1)，约等于 sigma²
+	r := max(1, int(math.Round(glowSigma(cfg))))
+	line := make([]float32, max(w, h)*4)
+	for pass := 0; pass < 3; pass++ {
+		for y := 0; y < h; y++ {
+			boxBlurLine(buf[y*w*4:], 4, w, r, line)
+		}
+		for x := 0; x < w; x++ {
+			boxBlurLine(buf[x*4:], w*4, h, r, line)
+		}
+	}
+
+	k := float32(cfg.Intensity)
+	for y := 0; y < h; y++ {
+		row := dst.Pix[dst.PixOffset(b.Min.X, b.Min.Y+y):][:w*4]
+		for i, v := range buf[y*w*4 : (y+1)*w*4] {
+			row[i] = uint8(min(255, float32(row[i])+v*k+0.5))
+		}
+	}
+}
+
+// boxBlurLine 对 data 中间隔 stride 的 n 个 RGBA 像素做半径为 r 的盒式模糊，line 为临时缓冲
+// 超出边界的像素视为透明
+func boxBlurLine(data []float32, stride, n, r int, line []float32) {
+	for i := 0; i < n; i++ {
+		copy(line[i*4:i*4+4], data[i*stride:i*stride+4])
+	}
+	var sum [4]float32
+	for i := 0; i < r && i < n; i++ {
+		for c := 0; c < 4; c++ {
+			sum[c] += line[i*4+c]
+		}
+	}
+	inv := 1 / float32(2*r+1)
+	for i := 0; i < n; i++ {
+		if in := i + r; in < n {
+			for c := 0; c < 4; c++ {
+				sum[c] += line[in*4+c]
+			}
+		}
+		if out := i - r - 1; out >= 0 {
+			for c := 0; c < 4; c++ {
+				sum[c] -= line[out*4+c]
+			}
+		}
+		for c := 0; c < 4; c++ {
+			data[i*stride+c] = sum[c] * inv
+		}
+	}
+}
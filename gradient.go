@@ -0,0 +1,124 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// GradientStop 渐变色标
+type GradientStop struct {
+	Pos   float64  `json:"pos"`   // 位置：0 = 轨迹头部，1 = 轨迹尾端
+	Color [4]uint8 `json:"color"` // RGBA
+}
+
+// oklab OKLab 感知均匀色彩空间中的颜色
+// 在 OKLab 中插值，渐变中段不会像 sRGB 插值那样发灰发暗
+type oklab struct {
+	L, A, B float64
+}
+
+// gradientStop 预先转换到 OKLab 的色标
+type gradientStop struct {
+	pos   float64
+	lab   oklab
+	alpha float64
+}
+
+// gradient 用于渲染的渐变，色标已按位置排序
+type gradient []gradientStop
+
+// newGradient 把配置中的色标转换为渲染用的渐变
+func newGradient(dst gradient, stops []GradientStop) gradient {
+	dst = dst[:0]
+	for _, s := range stops {
+		dst = append(dst, gradientStop{
+			pos:   s.Pos,
+			lab:   srgbToOklab(float64(s.Color[0])/255, float64(s.Color[1])/255, float64(s.Color[2])/255),
+			alpha: float64(s.Color[3]) / 255,
+		})
+	}
+	sort.SliceStable(dst, func(i, j int) bool {
+		return dst[i].pos < dst[j].pos
+	})
+	return dst
+}
+
+// At 返回位置 u 处的颜色 (sRGB，未预乘)
+func (g gradient) At(u float64) (r, gr, b, a float64) {
+	if len(g) == 0 {
+		return 1, 1, 1, 1
+	}
+	if u <= g[0].pos {
+		return g[0].rgba()
+	}
+	last := g[len(g)-1]
+	if u >= last.pos {
+		return last.rgba()
+	}
+	for i := 1; i < len(g); i++ {
+		if u <= g[i].pos {
+			s0, s1 := g[i-1], g[i]
+			t := 0.0
+			if s1.pos > s0.pos {
+				t = (u - s0.pos) / (s1.pos - s0.pos)
+			}
+			lab := oklab{
+				L: s0.lab.L + (s1.lab.L-s0.lab.L)*t,
+				A: s0.lab.A + (s1.lab.A-s0.lab.A)*t,
+				B: s0.lab.B + (s1.lab.B-s0.lab.B)*t,
+			}
+			r, gr, b = lab.toSRGB()
+			return r, gr, b, s0.alpha + (s1.alpha-s0.alpha)*t
+		}
+	}
+	return last.rgba()
+}
+
+func (s gradientStop) rgba() (r, g, b, a float64) {
+	r, g, b = s.lab.toSRGB()
+	return r, g, b, s.alpha
+}
+
+// srgbToOklab sRGB (0-1) 转 OKLab
+func srgbToOklab(r, g, b float64) oklab {
+	r, g, b = srgbToLinear(r), srgbToLinear(g), srgbToLinear(b)
+
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	return oklab{
+		L: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		A: 1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		B: 0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+// toSRGB OKLab 转 sRGB (0-1)，超出色域的分量会被截断
+func (c oklab) toSRGB() (r, g, b float64) {
+	l := c.L + 0.3963377774*c.A + 0.2158037573*c.B
+	m := c.L - 0.1055613458*c.A - 0.0638541728*c.B
+	s := c.L - 0.0894841775*c.A - 1.2914855480*c.B
+	l, m, s = l*l*l, m*m*m, s*s*s
+
+	r = 4.0767416621*l - 3.3077115913*m + 0.2309699292*s
+	g = -1.2684380046*l + 2.6097574011*m - 0.3413193965*s
+	b = -0.0041960863*l - 0.7034186147*m + 1.7076147010*s
+
+	return linearToSRGB(r), linearToSRGB(g), linearToSRGB(b)
+}
+
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) float64 {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
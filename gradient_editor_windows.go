@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/lxn/walk"
+	. "github.com/lxn/walk/declarative"
+	"github.com/lxn/win"
+)
+
+// gradientEditor 配置窗口中的渐变色标编辑器
+// 直接修改 cfg.TailGradient，每次修改后调用 onChange
+type gradientEditor struct {
+	cfg      *Config
+	owner    **walk.MainWindow
+	onChange func()
+
+	list  *walk.ListBox
+	pos   *walk.NumberEdit
+	alpha *walk.NumberEdit
+
+	// 颜色对话框的自定义颜色，在多次打开之间保留
+	customColors [16]win.COLORREF
+}
+
+func newGradientEditor(cfg *Config, owner **walk.MainWindow, onChange func()) *gradientEditor {
+	return &gradientEditor{cfg: cfg, owner: owner, onChange: onChange}
+}
+
+// Widget 返回编辑器的声明式控件
+func (e *gradientEditor) Widget() Widget {
+	return GroupBox{
+		Title:  T("Gradient"),
+		Layout: Grid{Columns: 2},
+		Children: []Widget{
+			ListBox{
+				AssignTo:              &e.list,
+				Model:                 e.items(),
+				OnCurrentIndexChanged: e.selectionChanged,
+				MinSize:               Size{Height: 60},
+				ColumnSpan:            2,
+			},
+			Label{Text: T("StopPos")},
+			NumberEdit{
+				AssignTo:       &e.pos,
+				MinValue:       0,
+				MaxValue:       1,
+				Decimals:       2,
+				OnValueChanged: e.posChanged,
+			},
+			Label{Text: T("StopAlpha")},
+			NumberEdit{
+				AssignTo:       &e.alpha,
+				MinValue:       0,
+				MaxValue:       255,
+				Decimals:       0,
+				OnValueChanged: e.alphaChanged,
+			},
+			Composite{
+				Layout:     HBox{MarginsZero: true},
+				ColumnSpan: 2,
+				Children: []Widget{
+					PushButton{Text: T("StopAdd"), OnClicked: e.add},
+					PushButton{Text: T("StopRemove"), OnClicked: e.remove},
+					PushButton{Text: T("StopColor"), OnClicked: e.pickColor},
+				},
+			},
+		},
+	}
+}
+
+// items 生成列表显示的文本
+func (e *gradientEditor) items() []string {
+	items := make([]string, len(e.cfg.TailGradient))
+	for i, s := range e.cfg.TailGradient {
+		items[i] = fmt.Sprintf("%.2f  #%02X%02X%02X  (%d)", s.Pos, s.Color[0], s.Color[1], s.Color[2], s.Color[3])
+	}
+	return items
+}
+
+// refresh 刷新列表并保持选中项
+func (e *gradientEditor) refresh(selected int) {
+	e.list.SetModel(e.items())
+	if selected >= 0 && selected < len(e.cfg.TailGradient) {
+		e.list.SetCurrentIndex(selected)
+	}
+	if e.onChange != nil {
+		e.onChange()
+	}
+}
+
+func (e *gradientEditor) selected() int {
+	idx := e.list.CurrentIndex()
+	if idx < 0 || idx >= len(e.cfg.TailGradient) {
+		return -1
+	}
+	return idx
+}
+
+func (e *gradientEditor) selectionChanged() {
+	if idx := e.selected(); idx >= 0 {
+		e.pos.SetValue(e.cfg.TailGradient[idx].Pos)
+		e.alpha.SetValue(float64(e.cfg.TailGradient[idx].Color[3]))
+	}
+}
+
+func (e *gradientEditor) alphaChanged() {
+	idx := e.selected()
+	a := uint8(e.alpha.Value())
+	if idx < 0 || e.cfg.TailGradient[idx].Color[3] == a {
+		return
+	}
+	e.cfg.TailGradient[idx].Color[3] = a
+	e.refresh(idx)
+}
+
+func (e *gradientEditor) posChanged() {
+	idx := e.selected()
+	if idx < 0 || e.cfg.TailGradient[idx].Pos == e.pos.Value() {
+		return
+	}
+	e.cfg.TailGradient[idx].Pos = e.pos.Value()
+	e.refresh(idx)
+}
+
+// add 添加色标：第一次添加时生成从轨迹颜色到透明的两个色标
+func (e *gradientEditor) add() {
+	if len(e.cfg.TailGradient) == 0 {
+		head := e.cfg.TailColor
+		tail := head
+		tail[3] = 0
+		e.cfg.TailGradient = []GradientStop{{Pos: 0, Color: head}, {Pos: 1, Color: tail}}
+		e.refresh(0)
+		return
+	}
+
+	// 在选中色标和下一个色标中间插入
+	idx := max(e.selected(), 0)
+	stop := e.cfg.TailGradient[idx]
+	next := 1.0
+	if idx+1 < len(e.cfg.TailGradient) {
+		next = e.cfg.TailGradient[idx+1].Pos
+	}
+	stop.Pos = (stop.Pos + next) / 2
+
+	stops := append([]GradientStop(nil), e.cfg.TailGradient[:idx+1]...)
+	stops = append(stops, stop)
+	e.cfg.TailGradient = append(stops, e.cfg.TailGradient[idx+1:]...)
+	e.refresh(idx + 1)
+}
+
+func (e *gradientEditor) remove() {
+	idx := e.selected()
+	if idx < 0 {
+		return
+	}
+	e.cfg.TailGradient = append(e.cfg.TailGradient[:idx], e.cfg.TailGradient[idx+1:]...)
+	e.refresh(min(idx, len(e.cfg.TailGradient)-1))
+}
+
+// pickColor 使用系统颜色对话框修改选中色标的颜色，透明度保持不变
+func (e *gradientEditor) pickColor() {
+	idx := e.selected()
+	if idx < 0 {
+		return
+	}
+	if chooseColor(*e.owner, &e.customColors, &e.cfg.TailGradient[idx].Color) {
+		e.refresh(idx)
+	}
+}
+
+// chooseColor 打开系统颜色对话框修改 c 的 RGB 分量，透明度保持不变
+// 用户取消时返回 false
+func chooseColor(owner *walk.MainWindow, custom *[16]win.COLORREF, c *[4]uint8) bool {
+	cc := win.CHOOSECOLOR{
+		RgbResult:    win.RGB(c[0], c[1], c[2]),
+		LpCustColors: custom,
+		Flags:        win.CC_RGBINIT | win.CC_FULLOPEN,
+	}
+	cc.LStructSize = uint32(unsafe.Sizeof(cc))
+	if owner != nil {
+		cc.HwndOwner = owner.Handle()
+	}
+	if !win.ChooseColor(&cc) {
+		return false
+	}
+
+	// COLORREF 的布局为 0x00BBGGRR
+	c[0] = byte(cc.RgbResult)
+	c[1] = byte(cc.RgbResult >> 8)
+	c[2] = byte(cc.RgbResult >> 16)
+	return true
+}
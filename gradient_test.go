@@ -0,0 +1,84 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// rgba8 把 0-1 的颜色换算回 8 位
+func rgba8(r, g, b, a float64) [4]uint8 {
+	return [4]uint8{
+		uint8(math.Round(r * 255)),
+		uint8(math.Round(g * 255)),
+		uint8(math.Round(b * 255)),
+		uint8(math.Round(a * 255)),
+	}
+}
+
+func TestGradientStops(t *testing.T) {
+	// 色标故意乱序，newGradient 按位置排序
+	stops := []GradientStop{
+		{Pos: 1, Color: [4]uint8{20, 40, 255, 0}},
+		{Pos: 0.25, Color: [4]uint8{255, 0, 0, 255}},
+		{Pos: 0.6, Color: [4]uint8{0, 200, 90, 128}},
+	}
+	g := newGradient(nil, stops)
+
+	tests := []struct {
+		u    float64
+		want [4]uint8
+	}{
+		// 正好落在色标上时为色标的颜色
+		{0.25, stops[1].Color},
+		{0.6, stops[2].Color},
+		{1, stops[0].Color},
+		// 色标范围之外取端点色标
+		{0, stops[1].Color},
+		{-1, stops[1].Color},
+		{2, stops[0].Color},
+	}
+	for _, tt := range tests {
+		if got := rgba8(g.At(tt.u)); got != tt.want {
+			t.Errorf("At(%v) = %v, want %v", tt.u, got, tt.want)
+		}
+	}
+
+	// 色标之间 alpha 线性插值
+	_, _, _, a := g.At(0.8)
+	if want := (128.0/255 + 0) / 2; math.Abs(a-want) > 1e-9 {
+		t.Errorf("alpha at 0.8 = %v, want %v", a, want)
+	}
+}
+
+func TestGradientOklabMidpoint(t *testing.T) {
+	// 同一颜色之间插值不变，黑白之间的中点是 OKLab 亮度的中点而不是 sRGB 的 50% 灰
+	tests := []struct {
+		name   string
+		c0, c1 [4]uint8
+		want   [4]uint8
+	}{
+		{"same color", [4]uint8{30, 160, 220, 255}, [4]uint8{30, 160, 220, 255}, [4]uint8{30, 160, 220, 255}},
+		{"black to white", [4]uint8{0, 0, 0, 255}, [4]uint8{255, 255, 255, 255}, [4]uint8{99, 99, 99, 255}},
+	}
+	for _, tt := range tests {
+		g := newGradient(nil, []GradientStop{{Pos: 0, Color: tt.c0}, {Pos: 1, Color: tt.c1}})
+		if got := rgba8(g.At(0.5)); got != tt.want {
+			t.Errorf("%s: At(0.5) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGradientEmpty(t *testing.T) {
+	if got := rgba8(newGradient(nil, nil).At(0.5)); got != [4]uint8{255, 255, 255, 255} {
+		t.Errorf("empty gradient At(0.5) = %v, want white", got)
+	}
+}
+
+func TestOklabRoundTrip(t *testing.T) {
+	for _, c := range [][3]uint8{{0, 0, 0}, {255, 255, 255}, {255, 0, 0}, {0, 255, 0}, {0, 0, 255}, {12, 200, 77}} {
+		r, g, b := srgbToOklab(float64(c[0])/255, float64(c[1])/255, float64(c[2])/255).toSRGB()
+		if got := rgba8(r, g, b, 1); [3]uint8{got[0], got[1], got[2]} != c {
+			t.Errorf("round trip of %v = %v", c, got)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"math"
+)
+
+// 光标光环样式
+const (
+	HaloRing = "ring" // 圆环
+	HaloFill = "fill" // 实心圆
+)
+
+const (
+	// 按下和松开时外观过渡的时长 (秒)
+	haloTransition = 0.12
+	// 实心光环的边数
+	haloSegments = 48
+)
+
+// HaloConfig 光标光环设置
+// 光标静止时也持续显示，方便演示时找到光标
+type HaloConfig struct {
+	Enabled bool     `json:"enabled"`
+	Style   string   `json:"style"`   // "ring", "fill"
+	Color   [4]uint8 `json:"color"`   // RGBA，alpha 为 0 表示使用轨迹颜色
+	Radius  float64  `json:"radius"`  // 半径 (像素)
+	Width   float64  `json:"width"`   // 圆环宽度 (像素)
+	Opacity float64  `json:"opacity"` // 透明度系数
+
+	// 按住任意鼠标按键时的外观，为空 (或 0) 的字段与正常外观相同
+	PressedStyle  string   `json:"pressed_style"`
+	PressedColor  [4]uint8 `json:"pressed_color"`
+	PressedRadius float64  `json:"pressed_radius"`
+}
+
+func defaultHaloConfig() HaloConfig {
+	return HaloConfig{
+		Enabled:       false,
+		Style:         HaloRing,
+		Color:         [4]uint8{255, 220, 0, 255},
+		Radius:        28,
+		Width:         4,
+		Opacity:       0.6,
+		PressedStyle:  HaloFill,
+		PressedRadius: 22,
+	}
+}
+
+// haloState 光环的位置和按下过渡进度
+type haloState struct {
+	X, Y    float64
+	pressed bool
+	press   float64 // 0 = 正常外观，1 = 按下外观
+}
+
+// SetHaloPressed 设置是否有鼠标按键处于按下状态，每帧调用
+func (tm *TraceManager) SetHaloPressed(pressed bool) {
+	tm.halo.pressed = pressed
+}
+
+// updateHalo 推进按下过渡动画，返回过渡是否仍在进行
+// 静止的光环不算活动，不会阻止空闲时降低刷新率
+func (tm *TraceManager) updateHalo(x, y, dt float64) bool {
+	h := &tm.halo
+	h.X, h.Y = x, y
+	if !tm.config.Halo.Enabled {
+		h.press = 0
+		return false
+	}
+
+	target := 0.0
+	if h.pressed {
+		target = 1
+	}
+	step := dt / haloTransition
+	if h.press < target {
+		h.press = math.Min(target, h.press+step)
+	} else {
+		h.press = math.Max(target, h.press-step)
+	}
+	return h.press != target
+}
+
+// buildHaloGeometry 生成光环的三角形
+// 正常和按下外观的样式或颜色不同时交叉淡入淡出，半径平滑过渡
+func (tm *TraceManager) buildHaloGeometry() {
+	cfg := &tm.config.Halo
+	if !cfg.Enabled {
+		return
+	}
+	h := &tm.halo
+
+	pressedStyle, pressedColor, pressedRadius := cfg.PressedStyle, cfg.PressedColor, cfg.PressedRadius
+	if pressedStyle == "" {
+		pressedStyle = cfg.Style
+	}
+	if pressedColor[3] == 0 {
+		pressedColor = cfg.Color
+	}
+	if pressedRadius <= 0 {
+		pressedRadius = cfg.Radius
+	}
+
+	radius := lerp(cfg.Radius, pressedRadius, h.press)
+	if h.press < 1 {
+		tm.addHalo(h.X, h.Y, radius, cfg.Style, tm.styleColor(cfg.Color, cfg.Opacity*(1-h.press)))
+	}
+	if h.press > 0 {
+		tm.addHalo(h.X, h.Y, radius, pressedStyle, tm.styleColor(pressedColor, cfg.Opacity*h.press))
+	}
+}
+
+func (tm *TraceManager) addHalo(x, y, radius float64, style string, c vertexColor) {
+	if c.A <= 0 || radius <= 0 {
+		return
+	}
+	if style == HaloFill {
+		tm.addDisc(x, y, radius, haloSegments, c)
+		return
+	}
+	// 圆环向内外各占一半宽度，半径对应圆环中线
+	width := math.Max(1, tm.config.Halo.Width)
+	tm.addRing(x, y, math.Max(0, radius-width/2), width, RippleCircle, c)
+}
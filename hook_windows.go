@@ -0,0 +1,187 @@
+package main
+
+import (
+	"log"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+const (
+	WH_KEYBOARD_LL = 13
+	WH_MOUSE_LL    = 14
+	WM_KEYDOWN     = 0x0100
+	WM_SYSKEYDOWN  = 0x0104
+	WM_MOUSEWHEEL  = 0x020A
+	WM_MOUSEHWHEEL = 0x020E
+	WHEEL_DELTA    = 120
+
+	// WM_UPDATE_KEYBOARD_HOOK 请求钩子线程按 wantKeyboard 安装或移除键盘钩子
+	WM_UPDATE_KEYBOARD_HOOK = win.WM_APP + 1
+)
+
+var (
+	procSetWindowsHookExW   = user32dll.NewProc("SetWindowsHookExW")
+	procCallNextHookEx      = user32dll.NewProc("CallNextHookEx")
+	procUnhookWindowsHookEx = user32dll.NewProc("UnhookWindowsHookEx")
+	procPostThreadMessageW  = user32dll.NewProc("PostThreadMessageW")
+)
+
+// MSLLHOOKSTRUCT 低级鼠标钩子的事件数据
+type MSLLHOOKSTRUCT struct {
+	Pt          win.POINT
+	MouseData   uint32
+	Flags       uint32
+	Time        uint32
+	DwExtraInfo uintptr
+}
+
+// KBDLLHOOKSTRUCT 低级键盘钩子的事件数据
+type KBDLLHOOKSTRUCT struct {
+	VkCode      uint32
+	ScanCode    uint32
+	Flags       uint32
+	Time        uint32
+	DwExtraInfo uintptr
+}
+
+// lowLevelHooks 全局低级输入钩子
+// GetAsyncKeyState 只能读取按键状态，滚轮和按键这类瞬时事件必须通过钩子获取。
+// 钩子回调运行在独立线程上，事件累积起来由 Poll 取走。
+// 键盘钩子只在需要显示按键时安装
+type lowLevelHooks struct {
+	// 累计的滚轮增量，原始单位 (WHEEL_DELTA = 1 格)
+	wheelX, wheelY atomic.Int64
+
+	// 按下的按键
+	mu   sync.Mutex
+	keys []KeyEvent
+
+	// 是否需要键盘钩子，以及钩子线程 ID (消息循环启动前为 0)
+	wantKeyboard atomic.Bool
+	threadID     atomic.Uint32
+	// 已安装的键盘钩子，只在钩子线程中访问
+	keyboardHook uintptr
+}
+
+// 钩子回调无法携带上下文，只能通过包级变量访问
+var activeHooks *lowLevelHooks
+
+// run 在当前 goroutine 锁定的线程上安装钩子并运行消息循环，直到 quitChan 关闭
+// 低级钩子要求安装线程持续处理消息，否则系统会跳过回调
+func (h *lowLevelHooks) run(quitChan chan struct{}) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	activeHooks = h
+	mouseHook, _, err := procSetWindowsHookExW.Call(WH_MOUSE_LL, mouseHookCallback, 0, 0)
+	if mouseHook == 0 {
+		log.Println("SetWindowsHookEx(WH_MOUSE_LL) failed:", err)
+		return
+	}
+	defer procUnhookWindowsHookEx.Call(mouseHook)
+
+	// 先记录线程 ID 再检查 wantKeyboard，之后的修改都会通过消息通知到这里
+	threadID := win.GetCurrentThreadId()
+	h.threadID.Store(threadID)
+	h.updateKeyboardHook()
+	defer func() {
+		h.wantKeyboard.Store(false)
+		h.updateKeyboardHook()
+	}()
+
+	// 退出时向本线程投递 WM_QUIT 结束消息循环
+	go func() {
+		<-quitChan
+		procPostThreadMessageW.Call(uintptr(threadID), win.WM_QUIT, 0, 0)
+	}()
+
+	var msg win.MSG
+	for win.GetMessage(&msg, 0, 0, 0) > 0 {
+		if msg.HWnd == 0 && msg.Message == WM_UPDATE_KEYBOARD_HOOK {
+			h.updateKeyboardHook()
+			continue
+		}
+		win.TranslateMessage(&msg)
+		win.DispatchMessage(&msg)
+	}
+}
+
+// setKeyboard 设置是否需要键盘钩子，可在任意线程调用
+func (h *lowLevelHooks) setKeyboard(on bool) {
+	if h.wantKeyboard.Swap(on) == on {
+		return
+	}
+	if threadID := h.threadID.Load(); threadID != 0 {
+		procPostThreadMessageW.Call(uintptr(threadID), WM_UPDATE_KEYBOARD_HOOK, 0, 0)
+	}
+}
+
+// updateKeyboardHook 按 wantKeyboard 安装或移除键盘钩子，只在钩子线程中调用
+// 键盘钩子失败时只是没有按键显示，滚轮仍然可用
+func (h *lowLevelHooks) updateKeyboardHook() {
+	want := h.wantKeyboard.Load()
+	switch {
+	case want && h.keyboardHook == 0:
+		hook, _, err := procSetWindowsHookExW.Call(WH_KEYBOARD_LL, keyboardHookCallback, 0, 0)
+		if hook == 0 {
+			log.Println("SetWindowsHookEx(WH_KEYBOARD_LL) failed:", err)
+			return
+		}
+		h.keyboardHook = hook
+	case !want && h.keyboardHook != 0:
+		procUnhookWindowsHookEx.Call(h.keyboardHook)
+		h.keyboardHook = 0
+		// 丢弃尚未取走的按键
+		h.takeKeys()
+	}
+}
+
+// takeWheel 取出自上次调用以来累计的滚轮增量 (格)
+func (h *lowLevelHooks) takeWheel() (dx, dy float64) {
+	return float64(h.wheelX.Swap(0)) / WHEEL_DELTA, float64(h.wheelY.Swap(0)) / WHEEL_DELTA
+}
+
+// takeKeys 取出自上次调用以来按下的按键
+func (h *lowLevelHooks) takeKeys() []KeyEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	keys := h.keys
+	h.keys = nil
+	return keys
+}
+
+var mouseHookCallback = syscall.NewCallback(func(nCode, wParam uintptr, info *MSLLHOOKSTRUCT) uintptr {
+	if int32(nCode) >= 0 && activeHooks != nil {
+		// 滚轮增量在 MouseData 的高 16 位，为有符号数
+		delta := int64(int16(info.MouseData >> 16))
+		switch wParam {
+		case WM_MOUSEWHEEL:
+			activeHooks.wheelY.Add(delta)
+		case WM_MOUSEHWHEEL:
+			activeHooks.wheelX.Add(delta)
+		}
+	}
+	ret, _, _ := procCallNextHookEx.Call(0, nCode, wParam, uintptr(unsafe.Pointer(info)))
+	return ret
+})
+
+var keyboardHookCallback = syscall.NewCallback(func(nCode, wParam uintptr, info *KBDLLHOOKSTRUCT) uintptr {
+	if int32(nCode) >= 0 && activeHooks != nil && (wParam == WM_KEYDOWN || wParam == WM_SYSKEYDOWN) {
+		if name := keyName(info.VkCode); name != "" {
+			ev := KeyEvent{Key: name, Mods: currentModifiers(), Secret: focusIsPassword(), Printable: isPrintableVK(info.VkCode)}
+			activeHooks.mu.Lock()
+			// Poll 长时间不取走时 (如窗口被挂起) 不再记录，避免无限增长
+			if len(activeHooks.keys) < 64 {
+				activeHooks.keys = append(activeHooks.keys, ev)
+			}
+			activeHooks.mu.Unlock()
+		}
+	}
+	ret, _, _ := procCallNextHookEx.Call(0, nCode, wParam, uintptr(unsafe.Pointer(info)))
+	return ret
+})
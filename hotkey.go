@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Hotkey 由修饰键和一个普通按键组成的热键，如 "Ctrl+Alt+L"
+type Hotkey struct {
+	Mods Modifier
+	Key  string // 与 KeyEvent.Key 相同的显示名称
+}
+
+// hotkeyAliases 按键名称的别名 (小写)，对应 KeyEvent.Key 使用的名称
+var hotkeyAliases = map[string]string{
+	"escape":   "Esc",
+	"return":   "Enter",
+	"delete":   "Del",
+	"insert":   "Ins",
+	"pageup":   "PgUp",
+	"pagedown": "PgDn",
+	"left":     "←",
+	"up":       "↑",
+	"right":    "→",
+	"down":     "↓",
+	"plus":     "=",
+	"minus":    "-",
+}
+
+// ParseHotkey 解析 "Ctrl+Alt+L" 形式的热键，最后一段为按键，其余为修饰键
+// 不区分大小写；按键名称与按键显示中的名称相同，另外接受 Escape、PageUp、Left 等别名
+func ParseHotkey(s string) (Hotkey, error) {
+	parts := strings.Split(s, "+")
+	key := strings.TrimSpace(parts[len(parts)-1])
+	if key == "" {
+		return Hotkey{}, fmt.Errorf("missing key in hotkey %q", s)
+	}
+	var h Hotkey
+	if len(parts) > 1 {
+		mods, err := ParseModifiers(strings.Join(parts[:len(parts)-1], "+"))
+		if err != nil {
+			return Hotkey{}, err
+		}
+		h.Mods = mods
+	}
+	if _, err := ParseModifiers(key); err == nil {
+		return Hotkey{}, fmt.Errorf("hotkey %q has no key besides modifiers", s)
+	}
+
+	if alias, ok := hotkeyAliases[strings.ToLower(key)]; ok {
+		key = alias
+	} else {
+		// 匹配时不区分大小写，这里只是让显示名称与按键显示一致，如 "l" -> "L"、"f5" -> "F5"
+		r, size := utf8.DecodeRuneInString(key)
+		key = string(unicode.ToUpper(r)) + key[size:]
+	}
+	h.Key = key
+	return h, nil
+}
+
+// String 返回 "Ctrl+Alt+L" 形式的名称
+func (h Hotkey) String() string {
+	if h.Mods == 0 {
+		return h.Key
+	}
+	return h.Mods.String() + "+" + h.Key
+}
+
+// Matches 返回按键事件是否为该热键，修饰键必须完全相同
+func (h Hotkey) Matches(ev KeyEvent) bool {
+	return h.Key != "" && ev.Mods == h.Mods && strings.EqualFold(ev.Key, h.Key)
+}
+
+// HotkeyConfig 全局热键设置，每项为 "Ctrl+Alt+P" 形式的热键，空字符串表示不使用
+// Windows 上通过 RegisterHotKey 注册为系统热键，Linux 上在键盘事件中匹配
+type HotkeyConfig struct {
+	Pause         string `json:"pause"`          // 暂停 / 恢复覆盖层
+	ToggleRipples string `json:"toggle_ripples"` // 开关点击波纹
+	CyclePreset   string `json:"cycle_preset"`   // 切换到下一个轨迹图层预设
+	Spotlight     string `json:"spotlight"`      // 开关演示聚光灯
+	Laser         string `json:"laser"`          // 开关激光笔模式
+	InkUndo       string `json:"ink_undo"`       // 撤销最后一条批注
+	InkClear      string `json:"ink_clear"`      // 清除所有批注
+	OpenConfig    string `json:"open_config"`    // 打开设置窗口
+}
+
+func defaultHotkeyConfig() HotkeyConfig {
+	return HotkeyConfig{
+		Pause:         "Ctrl+Alt+P",
+		ToggleRipples: "Ctrl+Alt+R",
+		CyclePreset:   "Ctrl+Alt+N",
+		Spotlight:     "Ctrl+Alt+S",
+		Laser:         "Ctrl+Alt+L",
+		InkUndo:       "Ctrl+Alt+Z",
+		InkClear:      "Ctrl+Alt+X",
+		OpenConfig:    "Ctrl+Alt+C",
+	}
+}
+
+// HotkeyBinding 一个已解析的热键及其操作
+type HotkeyBinding struct {
+	Name   string // HotkeyConfig 中的 JSON 名称，用于错误信息
+	Hotkey Hotkey
+	Action OverlayAction
+}
+
+// ParseHotkeys 解析 cfg.Hotkeys 中的所有热键，返回可用的绑定和每个问题对应的错误
+// 无法解析的热键被忽略；同一热键被多个操作使用时只保留第一个；
+// 开启屏幕批注时，修饰键与批注热键相同的热键按下时会开始画笔画，也视为冲突
+func ParseHotkeys(c *Config) ([]HotkeyBinding, []error) {
+	cfg := &c.Hotkeys
+	var bindings []HotkeyBinding
+	var errs []error
+
+	var inkMods Modifier
+	var inkErr error
+	if c.Ink.Enabled {
+		if inkMods, inkErr = ParseModifiers(c.Ink.Hotkey); inkErr != nil {
+			errs = append(errs, fmt.Errorf("ink: %w", inkErr))
+		}
+	}
+
+	entries := []struct {
+		name   string
+		value  string
+		action OverlayAction
+	}{
+		{"pause", cfg.Pause, ActionPause},
+		{"toggle_ripples", cfg.ToggleRipples, ActionToggleRipples},
+		{"cycle_preset", cfg.CyclePreset, ActionCyclePreset},
+		{"spotlight", cfg.Spotlight, ActionToggleSpotlight},
+		{"laser", cfg.Laser, ActionToggleLaser},
+		{"ink_undo", cfg.InkUndo, ActionUndoInk},
+		{"ink_clear", cfg.InkClear, ActionClearInk},
+		{"open_config", cfg.OpenConfig, ActionOpenConfig},
+	}
+
+	for _, e := range entries {
+		if strings.TrimSpace(e.value) == "" {
+			continue
+		}
+		h, err := ParseHotkey(e.value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", e.name, err))
+			continue
+		}
+		same := func(b HotkeyBinding) bool {
+			return b.Hotkey.Mods == h.Mods && strings.EqualFold(b.Hotkey.Key, h.Key)
+		}
+		if i := slices.IndexFunc(bindings, same); i >= 0 {
+			errs = append(errs, fmt.Errorf("%s: hotkey %s is already used by %s", e.name, h, bindings[i].Name))
+			continue
+		}
+		if c.Ink.Enabled && inkErr == nil && h.Mods == inkMods {
+			errs = append(errs, fmt.Errorf("%s: hotkey %s starts an ink stroke (ink hotkey %s)", e.name, h, c.Ink.Hotkey))
+			continue
+		}
+		bindings = append(bindings, HotkeyBinding{Name: e.name, Hotkey: h, Action: e.action})
+	}
+	return bindings, errs
+}
+
+// HotkeyMatcher 在按键事件中查找热键
+type HotkeyMatcher []HotkeyBinding
+
+// Match 返回 ev 对应的操作
+func (m HotkeyMatcher) Match(ev KeyEvent) (OverlayAction, bool) {
+	for _, b := range m {
+		if b.Hotkey.Matches(ev) {
+			return b.Action, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,10 @@
+package main
+
+// nativeHotkeys Linux 上没有注册全局热键，由 Game 在键盘事件中匹配
+const nativeHotkeys = false
+
+// ReloadHotkeys 在 Linux 上只检查热键，设置改变后 Game 会自动重新解析
+func ReloadHotkeys(cfg *Config) []error {
+	_, errs := ParseHotkeys(cfg)
+	return errs
+}
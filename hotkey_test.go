@@ -0,0 +1,186 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseHotkey(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Hotkey
+	}{
+		{"Ctrl+Alt+L", Hotkey{ModCtrl | ModAlt, "L"}},
+		{"ctrl+alt+l", Hotkey{ModCtrl | ModAlt, "L"}},
+		{" Ctrl + Shift + P ", Hotkey{ModCtrl | ModShift, "P"}},
+		{"Control+Win+F5", Hotkey{ModCtrl | ModSuper, "F5"}},
+		{"f5", Hotkey{0, "F5"}},
+		{"Alt+Escape", Hotkey{ModAlt, "Esc"}},
+		{"Ctrl+PageDown", Hotkey{ModCtrl, "PgDn"}},
+		{"Ctrl+Left", Hotkey{ModCtrl, "←"}},
+		{"Ctrl+←", Hotkey{ModCtrl, "←"}},
+		{"Ctrl+Plus", Hotkey{ModCtrl, "="}},
+	}
+	for _, tt := range tests {
+		got, err := ParseHotkey(tt.in)
+		if err != nil {
+			t.Errorf("ParseHotkey(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseHotkey(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseHotkeyErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"Ctrl+",
+		"Ctrl+Shift",
+		"Alt",
+		"Hyper+L",
+		"Ctrl++L",
+	} {
+		if h, err := ParseHotkey(in); err == nil {
+			t.Errorf("ParseHotkey(%q) = %+v, want error", in, h)
+		}
+	}
+}
+
+func TestHotkeyString(t *testing.T) {
+	for _, in := range []string{"Ctrl+Alt+L", "F5", "Shift+Esc"} {
+		h, err := ParseHotkey(in)
+		if err != nil {
+			t.Fatalf("ParseHotkey(%q): %v", in, err)
+		}
+		if got := h.String(); got != in {
+			t.Errorf("ParseHotkey(%q).String() = %q", in, got)
+		}
+	}
+}
+
+// hotkeyTestConfig 返回只设置了 hotkeys 的配置，其余热键为空
+func hotkeyTestConfig(hotkeys HotkeyConfig) *Config {
+	c := DefaultConfig()
+	c.Hotkeys = hotkeys
+	return c
+}
+
+func TestParseHotkeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		hotkeys  HotkeyConfig
+		ink      string // 非空时开启屏幕批注
+		bindings []string
+		errs     []string
+	}{
+		{
+			name:     "defaults",
+			hotkeys:  defaultHotkeyConfig(),
+			bindings: []string{"pause", "toggle_ripples", "cycle_preset", "spotlight", "laser", "ink_undo", "ink_clear", "open_config"},
+		},
+		{
+			name:     "empty entries are disabled",
+			hotkeys:  HotkeyConfig{Pause: "Ctrl+Alt+P", Laser: "  "},
+			bindings: []string{"pause"},
+		},
+		{
+			name:     "invalid entry",
+			hotkeys:  HotkeyConfig{Pause: "Ctrl+Alt+P", Laser: "Hyper+L"},
+			bindings: []string{"pause"},
+			errs:     []string{"laser: "},
+		},
+		{
+			name:     "duplicate keeps first",
+			hotkeys:  HotkeyConfig{Pause: "Ctrl+Alt+P", Spotlight: "Ctrl+Alt+P"},
+			bindings: []string{"pause"},
+			errs:     []string{"spotlight: hotkey Ctrl+Alt+P is already used by pause"},
+		},
+		{
+			name:     "duplicate ignores case and spelling",
+			hotkeys:  HotkeyConfig{Pause: "Ctrl+Alt+Esc", Spotlight: "control+alt+escape"},
+			bindings: []string{"pause"},
+			errs:     []string{"spotlight: hotkey Ctrl+Alt+Esc is already used by pause"},
+		},
+		{
+			name:     "same key with other modifiers",
+			hotkeys:  HotkeyConfig{Pause: "Ctrl+Alt+P", Spotlight: "Ctrl+Shift+P"},
+			bindings: []string{"pause", "spotlight"},
+		},
+		{
+			name:     "ink chord conflict",
+			hotkeys:  HotkeyConfig{Pause: "Ctrl+Alt+P", InkUndo: "Ctrl+Shift+Z"},
+			ink:      "Ctrl+Shift",
+			bindings: []string{"pause"},
+			errs:     []string{"ink_undo: hotkey Ctrl+Shift+Z starts an ink stroke"},
+		},
+		{
+			name:     "invalid ink chord",
+			hotkeys:  HotkeyConfig{Pause: "Ctrl+Alt+P"},
+			ink:      "Ctrl+Q",
+			bindings: []string{"pause"},
+			errs:     []string{"ink: "},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := hotkeyTestConfig(tt.hotkeys)
+			if tt.ink != "" {
+				c.Ink.Enabled = true
+				c.Ink.Hotkey = tt.ink
+			}
+			bindings, errs := ParseHotkeys(c)
+
+			var names []string
+			for _, b := range bindings {
+				names = append(names, b.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.bindings, ",") {
+				t.Errorf("bindings = %v, want %v", names, tt.bindings)
+			}
+			if len(errs) != len(tt.errs) {
+				t.Fatalf("errors = %v, want %d", errs, len(tt.errs))
+			}
+			for i, err := range errs {
+				if !strings.HasPrefix(err.Error(), tt.errs[i]) {
+					t.Errorf("error %d = %q, want prefix %q", i, err, tt.errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHotkeyMatcher(t *testing.T) {
+	bindings, errs := ParseHotkeys(hotkeyTestConfig(HotkeyConfig{
+		Pause:     "Ctrl+Alt+P",
+		Spotlight: "Ctrl+Shift+P",
+		Laser:     "F8",
+	}))
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	m := HotkeyMatcher(bindings)
+
+	tests := []struct {
+		ev   KeyEvent
+		want OverlayAction
+		ok   bool
+	}{
+		{KeyEvent{Key: "P", Mods: ModCtrl | ModAlt}, ActionPause, true},
+		{KeyEvent{Key: "p", Mods: ModCtrl | ModAlt}, ActionPause, true},
+		{KeyEvent{Key: "P", Mods: ModCtrl | ModShift}, ActionToggleSpotlight, true},
+		{KeyEvent{Key: "F8"}, ActionToggleLaser, true},
+		// 修饰键必须完全相同
+		{KeyEvent{Key: "P", Mods: ModCtrl}, 0, false},
+		{KeyEvent{Key: "P", Mods: ModCtrl | ModAlt | ModShift}, 0, false},
+		{KeyEvent{Key: "F8", Mods: ModShift}, 0, false},
+		{KeyEvent{Key: "Q", Mods: ModCtrl | ModAlt}, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := m.Match(tt.ev)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Match(%+v) = %v, %v, want %v, %v", tt.ev, got, ok, tt.want, tt.ok)
+		}
+	}
+}
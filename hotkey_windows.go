@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/lxn/win"
+)
+
+// nativeHotkeys Windows 上全局热键由 RegisterHotKey 注册，在托盘窗口中接收 WM_HOTKEY
+const nativeHotkeys = true
+
+const (
+	MOD_ALT      = 0x0001
+	MOD_CONTROL  = 0x0002
+	MOD_SHIFT    = 0x0004
+	MOD_WIN      = 0x0008
+	MOD_NOREPEAT = 0x4000
+
+	WM_HOTKEY = 0x0312
+	// WM_RELOAD_HOTKEYS 请求托盘线程按新的设置重新注册热键
+	WM_RELOAD_HOTKEYS = win.WM_USER + 2
+)
+
+var (
+	procRegisterHotKey   = user32dll.NewProc("RegisterHotKey")
+	procUnregisterHotKey = user32dll.NewProc("UnregisterHotKey")
+)
+
+// registeredHotkey 已注册的热键，ID 为 RegisterHotKey 使用的热键 ID
+type registeredHotkey struct {
+	ID uintptr
+	HotkeyBinding
+}
+
+// 托盘窗口和已注册的热键，只在托盘线程中访问
+var (
+	trayHwnd          win.HWND
+	registeredHotkeys []registeredHotkey
+)
+
+// ReloadHotkeys 交给托盘线程的设置和托盘线程返回的结果
+var (
+	reloadMu     sync.Mutex // 同一时间只处理一个 ReloadHotkeys
+	reloadConfig *Config
+	reloadErrs   []error
+)
+
+// vkFromName 返回按键显示名称对应的虚拟键码
+func vkFromName(name string) (uint32, bool) {
+	for vk := uint32(1); vk < 256; vk++ {
+		if n := keyName(vk); n != "" && strings.EqualFold(n, name) {
+			return vk, true
+		}
+	}
+	return 0, false
+}
+
+// hotkeyModifiers 把修饰键转换为 RegisterHotKey 的 fsModifiers
+func hotkeyModifiers(mods Modifier) uintptr {
+	flags := uintptr(MOD_NOREPEAT)
+	if mods&ModCtrl != 0 {
+		flags |= MOD_CONTROL
+	}
+	if mods&ModAlt != 0 {
+		flags |= MOD_ALT
+	}
+	if mods&ModShift != 0 {
+		flags |= MOD_SHIFT
+	}
+	if mods&ModSuper != 0 {
+		flags |= MOD_WIN
+	}
+	return flags
+}
+
+// registerHotkeys 在 hwnd 上注册 cfg 中的所有热键，返回无法使用的热键对应的错误
+// 无法解析、重复、按键未知或已被其他程序占用的热键记录日志后跳过
+func registerHotkeys(hwnd win.HWND, cfg *Config) []error {
+	bindings, errs := ParseHotkeys(cfg)
+	for i, b := range bindings {
+		id := uintptr(i + 1)
+		vk, ok := vkFromName(b.Hotkey.Key)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown key %q", b.Name, b.Hotkey.Key))
+			continue
+		}
+		if ret, _, err := procRegisterHotKey.Call(uintptr(hwnd), id, hotkeyModifiers(b.Hotkey.Mods), uintptr(vk)); ret == 0 {
+			errs = append(errs, fmt.Errorf("%s: %s is already in use by another program (%v)", b.Name, b.Hotkey, err))
+			continue
+		}
+		registeredHotkeys = append(registeredHotkeys, registeredHotkey{ID: id, HotkeyBinding: b})
+	}
+	for _, err := range errs {
+		log.Println("Hotkey:", err)
+	}
+	return errs
+}
+
+// unregisterHotkeys 注销 hwnd 上的所有热键
+func unregisterHotkeys(hwnd win.HWND) {
+	for _, h := range registeredHotkeys {
+		procUnregisterHotKey.Call(uintptr(hwnd), h.ID)
+	}
+	registeredHotkeys = registeredHotkeys[:0]
+}
+
+// hotkeyAction 返回 WM_HOTKEY 收到的热键 ID 对应的操作
+func hotkeyAction(id uintptr) (OverlayAction, bool) {
+	for _, h := range registeredHotkeys {
+		if h.ID == id {
+			return h.Action, true
+		}
+	}
+	return 0, false
+}
+
+// ReloadHotkeys 按 cfg 重新注册全局热键，返回无法使用的热键对应的错误
+// 可在任意线程调用，等托盘线程注册完成后返回
+func ReloadHotkeys(cfg *Config) []error {
+	if trayHwnd == 0 {
+		_, errs := ParseHotkeys(cfg)
+		return errs
+	}
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	reloadConfig = cfg.Clone()
+	win.SendMessage(trayHwnd, WM_RELOAD_HOTKEYS, 0, 0)
+	return reloadErrs
+}
+
+// reloadHotkeys 在托盘线程中处理 ReloadHotkeys
+func reloadHotkeys(hwnd win.HWND) {
+	unregisterHotkeys(hwnd)
+	reloadErrs = registerHotkeys(hwnd, reloadConfig)
+}
@@ -1,14 +1,5 @@
 package main
 
-import (
-	"syscall"
-)
-
-var (
-	kernel32dll                  = syscall.NewLazyDLL("kernel32.dll")
-	procGetUserDefaultUILanguage = kernel32dll.NewProc("GetUserDefaultUILanguage")
-)
-
 // Language 语言代码
 type Language int
 
@@ -22,52 +13,396 @@ var currentLang Language
 // i18n 字符串映射
 var i18nStrings = map[Language]map[string]string{
 	LangEnglish: {
-		"Title":          "Mouse Flow Configuration",
-		"Appearance":     "Appearance",
-		"Length":         "Length:",
-		"Width":          "Width:",
-		"ColorEffects":   "Color & Effects",
-		"RainbowMode":    "Rainbow Mode",
-		"ClickRipple":    "Click Ripple Effect",
-		"Red":            "Red:",
-		"Green":          "Green:",
-		"Blue":           "Blue:",
-		"SaveClose":      "Save & Close",
-		"TrayTip":        "Mouse Flow - Mouse Trace Tool",
-		"MenuConfig":     "Configuration",
-		"MenuExit":       "Exit",
-		"Language":       "Language:",
-		"LangAuto":       "Auto",
-		"LangEn":         "English",
-		"LangZh":         "Chinese",
-		"RippleSettings": "Ripple Settings",
-		"RippleGrowth":   "Growth Speed:",
-		"RippleDecay":    "Decay Speed:",
-		"RippleWidth":    "Ripple Width:",
+		"Title":             "Mouse Flow Configuration",
+		"Appearance":        "Appearance",
+		"Length":            "Length:",
+		"Width":             "Width:",
+		"Lifetime":          "Lifetime (s):",
+		"Smoothing":         "Smoothing:",
+		"SmoothNone":        "None",
+		"SmoothCatmullRom":  "Catmull-Rom",
+		"SmoothCentripetal": "Centripetal",
+		"Tension":           "Tension:",
+		"WidthProfile":      "Width Curve:",
+		"AlphaProfile":      "Opacity Curve:",
+		"ProfileLinear":     "Linear",
+		"ProfileConstant":   "Constant",
+		"ProfileEaseIn":     "Ease In",
+		"ProfileEaseOut":    "Ease Out",
+		"ProfileBlade":      "Blade",
+		"ProfileCustom":     "Custom (config.json)",
+		"Gradient":          "Gradient (empty = single color)",
+		"StopPos":           "Position (0 head - 1 tail):",
+		"StopAlpha":         "Opacity (0-255):",
+		"StopAdd":           "Add",
+		"StopRemove":        "Remove",
+		"StopColor":         "Color...",
+		"RainbowStyle":      "Rainbow Style:",
+		"RainbowUniform":    "Cycle over time",
+		"RainbowTrail":      "Along the trail",
+		"RainbowSpeed":      "By speed",
+		"RainbowSpace":      "Color Space:",
+		"RainbowPeriod":     "Cycle Period (s):",
+		"RainbowSpread":     "Hue Spread:",
+		"SpeedStyle":        "Speed Reactive",
+		"SpeedEnabled":      "Style trail by speed",
+		"SpeedMin":          "Slow Speed (px/s):",
+		"SpeedMax":          "Fast Speed (px/s):",
+		"FastWidth":         "Width When Fast:",
+		"FastBrighten":      "Brighten When Fast:",
+		"ColorEffects":      "Color & Effects",
+		"RainbowMode":       "Rainbow Mode",
+		"ClickRipple":       "Click Ripple Effect",
+		"Red":               "Red:",
+		"Green":             "Green:",
+		"Blue":              "Blue:",
+		"SaveClose":         "Save & Close",
+		"TrayTip":           "Mouse Flow - Mouse Trace Tool",
+		"MenuConfig":        "Configuration",
+		"MenuExit":          "Exit",
+		"Language":          "Language:",
+		"LangAuto":          "Auto",
+		"LangEn":            "English",
+		"LangZh":            "Chinese",
+		"RippleSettings":    "Ripple Settings",
+		"RippleGrowth":      "Growth (px/s):",
+		"RippleDuration":    "Duration (s):",
+		"RippleWidth":       "Ripple Width:",
+		"TabTrail":          "Trail",
+		"TabColor":          "Color",
+		"TabRipple":         "Ripple",
+		"ButtonRipples":     "Per-Button Ripples",
+		"MouseButton":       "Button:",
+		"ButtonLeft":        "Left",
+		"ButtonRight":       "Right",
+		"ButtonMiddle":      "Middle",
+		"ButtonX1":          "Back (X1)",
+		"ButtonX2":          "Forward (X2)",
+		"RippleEnabled":     "Show ripple for this button",
+		"RippleShape":       "Shape:",
+		"ShapeCircle":       "Circle",
+		"ShapeSquare":       "Square",
+		"ShapeDiamond":      "Diamond",
+		"ShapeTriangle":     "Triangle",
+		"RippleUseTrail":    "Use trail color",
+		"RippleZeroHint":    "0 = use the global ripple settings",
+		"ModifierRipples":   "Modifier Clicks",
+		"ModifierEnabled":   "Color ripples by held Ctrl/Alt/Shift/Win",
+		"ModifierLabel":     "Show modifier label next to the ripple",
+		"Gestures":          "Gestures",
+		"GesturesEnabled":   "Show double-click, long-press and drag",
+		"DoubleClickTime":   "Double-click time (s):",
+		"LongPressTime":     "Long-press time (s):",
+		"DragShape":         "Drag shape:",
+		"DragRect":          "Rectangle",
+		"DragLine":          "Line",
+		"TabInput":          "Input",
+		"WheelIndicator":    "Scroll Wheel",
+		"WheelEnabled":      "Show scroll direction arrows",
+		"WheelSize":         "Arrow size:",
+		"WheelDuration":     "Duration (s):",
+		"TabKeys":           "Keys",
+		"Keystrokes":        "Keystroke Overlay",
+		"KeysEnabled":       "Show pressed keys",
+		"KeysPosition":      "Position:",
+		"PosCursor":         "Next to cursor",
+		"PosTopLeft":        "Top left",
+		"PosTopRight":       "Top right",
+		"PosBottomLeft":     "Bottom left",
+		"PosBottomRight":    "Bottom right",
+		"KeysFont":          "Font file (empty = built-in):",
+		"KeysFontSize":      "Font size:",
+		"KeysDuration":      "Duration (s):",
+		"KeysTyping":        "Show typed text (off = shortcuts only)",
+		"KeysPrivacy":       "Mask input in password fields",
+		"TabEffects":        "Effects",
+		"Particles":         "Particles",
+		"ParticleSparkle":   "Sparkles around the cursor",
+		"ParticleEmber":     "Embers rising from the trail",
+		"ParticleSmoke":     "Smoke behind the cursor",
+		"ParticleConfetti":  "Confetti on click",
+		"ParticleHint":      "Fine-tune emitters in config.json (particles)",
+		"Glow":              "Glow",
+		"GlowEnabled":       "Soft glow under the trail",
+		"GlowRadius":        "Glow radius:",
+		"GlowIntensity":     "Glow intensity:",
+		"Shaders":           "Shaders",
+		"TrailShader":       "Trail shader:",
+		"RippleShader":      "Ripple shader:",
+		"ShaderNone":        "None (solid colour)",
+		"Brush":             "Texture Brush",
+		"BrushPath":         "PNG texture (empty = solid):",
+		"BrushMode":         "Brush mode:",
+		"BrushStretch":      "Stretch along the trail",
+		"BrushTile":         "Tile along the trail",
+		"BrushStamp":        "Stamp along the trail",
+		"BrushTint":         "Tint the texture with the trail colour",
+		"TrailLayers":       "Trail layers:",
+		"LayerNone":         "Single trail",
+		"LayerGlowCore":     "Soft outer glow + bright core",
+		"LayerGhost":        "Trail + delayed ghost",
+		"LayerCustom":       "Custom (config.json)",
+		"TabCursor":         "Cursor",
+		"Halo":              "Cursor Halo",
+		"HaloEnabled":       "Always show a halo around the cursor",
+		"HaloStyle":         "Style:",
+		"HaloRing":          "Ring",
+		"HaloFill":          "Filled circle",
+		"HaloRadius":        "Radius:",
+		"HaloOpacity":       "Opacity:",
+		"HaloColor":         "Colour...",
+		"HaloPressedColor":  "Pressed colour...",
+		"Shake":             "Shake to Find Cursor",
+		"ShakeEnabled":      "Show a locator ring when the mouse is shaken",
+		"ShakeStyle":        "Style:",
+		"ShakeReversals":    "Direction changes needed:",
+		"ShakeDistance":     "Minimum swing (px):",
+		"ShakeRadius":       "Radius:",
+		"ShakeColor":        "Colour...",
+		"Spotlight":         "Presenter Spotlight",
+		"SpotlightEnabled":  "Dim the screen except around the cursor",
+		"SpotlightShape":    "Shape:",
+		"SpotlightCircle":   "Circle",
+		"SpotlightRect":     "Rectangle",
+		"SpotlightRadius":   "Radius:",
+		"SpotlightWidth":    "Width:",
+		"SpotlightHeight":   "Height:",
+		"SpotlightFeather":  "Soft edge (px):",
+		"SpotlightDim":      "Dim level:",
+		"MenuSpotlight":     "Spotlight",
+		"TabPresenter":      "Presenter",
+		"Ink":               "Screen Annotation",
+		"InkEnabled":        "Draw while holding the hotkey",
+		"InkHotkey":         "Hotkey (modifiers):",
+		"InkTool":           "Tool:",
+		"InkPen":            "Pen",
+		"InkHighlighter":    "Highlighter",
+		"InkArrow":          "Arrow",
+		"InkRect":           "Rectangle",
+		"InkEllipse":        "Ellipse",
+		"InkWidth":          "Width:",
+		"InkFadeAfter":      "Fade after (s, 0 = keep):",
+		"InkColor":          "Colour...",
+		"InkHint":           "Undo and clear are in the tray menu.",
+		"MenuInkUndo":       "Undo annotation",
+		"MenuInkClear":      "Clear annotations",
+		"Laser":             "Laser Pointer",
+		"LaserEnabled":      "Laser pointer mode",
+		"LaserWidth":        "Beam width:",
+		"LaserDot":          "Dot radius:",
+		"LaserAutoOff":      "Back to normal after idle (s, 0 = never):",
+		"LaserHide":         "Hide the system cursor",
+		"LaserColor":        "Colour...",
+		"MenuLaser":         "Laser pointer",
+		"MenuPause":         "Pause effects",
+		"TabHotkeys":        "Hotkeys",
+		"Hotkeys":           "Global Hotkeys",
+		"HotkeyPause":       "Pause / resume:",
+		"HotkeyRipples":     "Toggle ripples:",
+		"HotkeyPreset":      "Next layer preset:",
+		"HotkeySpotlight":   "Toggle spotlight:",
+		"HotkeyLaser":       "Toggle laser pointer:",
+		"HotkeyInkUndo":     "Undo annotation:",
+		"HotkeyInkClear":    "Clear annotations:",
+		"HotkeyConfig":      "Open settings:",
+		"HotkeysHint":       "e.g. Ctrl+Alt+P; leave empty to disable. Takes effect after closing this window.",
+		"HotkeyErrors":      "These hotkeys will not work:",
 	},
 	LangChinese: {
-		"Title":          "鼠标轨迹配置",
-		"Appearance":     "外观设置",
-		"Length":         "轨迹长度:",
-		"Width":          "轨迹宽度:",
-		"ColorEffects":   "颜色与特效",
-		"RainbowMode":    "彩虹模式",
-		"ClickRipple":    "点击波纹特效",
-		"Red":            "红色 (R):",
-		"Green":          "绿色 (G):",
-		"Blue":           "蓝色 (B):",
-		"SaveClose":      "保存并关闭",
-		"TrayTip":        "Mouse Flow - 鼠标痕迹工具",
-		"MenuConfig":     "配置",
-		"MenuExit":       "退出",
-		"Language":       "语言设置:",
-		"LangAuto":       "自动 (跟随系统)",
-		"LangEn":         "English",
-		"LangZh":         "简体中文",
-		"RippleSettings": "波纹设置",
-		"RippleGrowth":   "扩散速度:",
-		"RippleDecay":    "消失速度:",
-		"RippleWidth":    "波纹宽度:",
+		"Title":             "鼠标轨迹配置",
+		"Appearance":        "外观设置",
+		"Length":            "轨迹长度:",
+		"Width":             "轨迹宽度:",
+		"Lifetime":          "存活时间 (秒):",
+		"Smoothing":         "平滑方式:",
+		"SmoothNone":        "无",
+		"SmoothCatmullRom":  "Catmull-Rom 样条",
+		"SmoothCentripetal": "向心样条",
+		"Tension":           "平滑张力:",
+		"WidthProfile":      "宽度曲线:",
+		"AlphaProfile":      "透明度曲线:",
+		"ProfileLinear":     "线性",
+		"ProfileConstant":   "恒定",
+		"ProfileEaseIn":     "缓入",
+		"ProfileEaseOut":    "缓出",
+		"ProfileBlade":      "刀光",
+		"ProfileCustom":     "自定义 (config.json)",
+		"Gradient":          "渐变色 (为空时使用单色)",
+		"StopPos":           "位置 (0 头部 - 1 尾端):",
+		"StopAlpha":         "不透明度 (0-255):",
+		"StopAdd":           "添加",
+		"StopRemove":        "删除",
+		"StopColor":         "颜色...",
+		"RainbowStyle":      "彩虹样式:",
+		"RainbowUniform":    "随时间循环",
+		"RainbowTrail":      "沿轨迹分布",
+		"RainbowSpeed":      "随速度变化",
+		"RainbowSpace":      "色彩空间:",
+		"RainbowPeriod":     "循环周期 (秒):",
+		"RainbowSpread":     "色相跨度:",
+		"SpeedStyle":        "速度响应",
+		"SpeedEnabled":      "根据速度调整轨迹样式",
+		"SpeedMin":          "慢速 (像素/秒):",
+		"SpeedMax":          "快速 (像素/秒):",
+		"FastWidth":         "快速时宽度系数:",
+		"FastBrighten":      "快速时提亮比例:",
+		"ColorEffects":      "颜色与特效",
+		"RainbowMode":       "彩虹模式",
+		"ClickRipple":       "点击波纹特效",
+		"Red":               "红色 (R):",
+		"Green":             "绿色 (G):",
+		"Blue":              "蓝色 (B):",
+		"SaveClose":         "保存并关闭",
+		"TrayTip":           "Mouse Flow - 鼠标痕迹工具",
+		"MenuConfig":        "配置",
+		"MenuExit":          "退出",
+		"Language":          "语言设置:",
+		"LangAuto":          "自动 (跟随系统)",
+		"LangEn":            "English",
+		"LangZh":            "简体中文",
+		"RippleSettings":    "波纹设置",
+		"RippleGrowth":      "扩散速度 (像素/秒):",
+		"RippleDuration":    "持续时间 (秒):",
+		"RippleWidth":       "波纹宽度:",
+		"TabTrail":          "轨迹",
+		"TabColor":          "颜色",
+		"TabRipple":         "波纹",
+		"ButtonRipples":     "按键波纹",
+		"MouseButton":       "鼠标按键:",
+		"ButtonLeft":        "左键",
+		"ButtonRight":       "右键",
+		"ButtonMiddle":      "中键",
+		"ButtonX1":          "后退键 (X1)",
+		"ButtonX2":          "前进键 (X2)",
+		"RippleEnabled":     "该按键显示波纹",
+		"RippleShape":       "形状:",
+		"ShapeCircle":       "圆形",
+		"ShapeSquare":       "方形",
+		"ShapeDiamond":      "菱形",
+		"ShapeTriangle":     "三角形",
+		"RippleUseTrail":    "使用轨迹颜色",
+		"RippleZeroHint":    "数值为 0 时使用全局波纹设置",
+		"ModifierRipples":   "修饰键点击",
+		"ModifierEnabled":   "按住 Ctrl/Alt/Shift/Win 点击时改变波纹颜色",
+		"ModifierLabel":     "在波纹旁显示修饰键名称",
+		"Gestures":          "手势",
+		"GesturesEnabled":   "显示双击、长按和拖动",
+		"DoubleClickTime":   "双击间隔 (秒):",
+		"LongPressTime":     "长按时长 (秒):",
+		"DragShape":         "拖动图形:",
+		"DragRect":          "矩形框",
+		"DragLine":          "直线",
+		"TabInput":          "输入",
+		"WheelIndicator":    "滚轮",
+		"WheelEnabled":      "显示滚动方向箭头",
+		"WheelSize":         "箭头大小:",
+		"WheelDuration":     "持续时间 (秒):",
+		"TabKeys":           "按键",
+		"Keystrokes":        "按键显示",
+		"KeysEnabled":       "显示按下的按键",
+		"KeysPosition":      "显示位置:",
+		"PosCursor":         "光标旁",
+		"PosTopLeft":        "左上角",
+		"PosTopRight":       "右上角",
+		"PosBottomLeft":     "左下角",
+		"PosBottomRight":    "右下角",
+		"KeysFont":          "字体文件 (为空时使用内置字体):",
+		"KeysFontSize":      "字号:",
+		"KeysDuration":      "持续时间 (秒):",
+		"KeysTyping":        "显示输入的文字 (关闭时只显示快捷键)",
+		"KeysPrivacy":       "在密码框中隐藏输入内容",
+		"TabEffects":        "特效",
+		"Particles":         "粒子",
+		"ParticleSparkle":   "光标周围的火花",
+		"ParticleEmber":     "轨迹上升起的余烬",
+		"ParticleSmoke":     "光标后方的烟雾",
+		"ParticleConfetti":  "点击时喷出彩纸",
+		"ParticleHint":      "发射器的详细参数请在 config.json (particles) 中调整",
+		"Glow":              "辉光",
+		"GlowEnabled":       "在轨迹下方叠加柔和的辉光",
+		"GlowRadius":        "辉光半径:",
+		"GlowIntensity":     "辉光强度:",
+		"Shaders":           "着色器",
+		"TrailShader":       "轨迹着色器:",
+		"RippleShader":      "波纹着色器:",
+		"ShaderNone":        "无 (纯色)",
+		"Brush":             "纹理笔刷",
+		"BrushPath":         "PNG 纹理 (为空时使用纯色):",
+		"BrushMode":         "笔刷模式:",
+		"BrushStretch":      "沿轨迹拉伸",
+		"BrushTile":         "沿轨迹平铺",
+		"BrushStamp":        "沿轨迹盖章",
+		"BrushTint":         "用轨迹颜色给纹理着色",
+		"TrailLayers":       "轨迹图层:",
+		"LayerNone":         "单条轨迹",
+		"LayerGlowCore":     "柔和外层 + 明亮内芯",
+		"LayerGhost":        "轨迹 + 延迟残影",
+		"LayerCustom":       "自定义 (config.json)",
+		"TabCursor":         "光标",
+		"Halo":              "光标光环",
+		"HaloEnabled":       "始终在光标周围显示光环",
+		"HaloStyle":         "样式:",
+		"HaloRing":          "圆环",
+		"HaloFill":          "实心圆",
+		"HaloRadius":        "半径:",
+		"HaloOpacity":       "不透明度:",
+		"HaloColor":         "颜色...",
+		"HaloPressedColor":  "按下时颜色...",
+		"Shake":             "晃动找光标",
+		"ShakeEnabled":      "晃动鼠标时显示定位环",
+		"ShakeStyle":        "样式:",
+		"ShakeReversals":    "需要的方向反转次数:",
+		"ShakeDistance":     "最小摆动距离 (像素):",
+		"ShakeRadius":       "半径:",
+		"ShakeColor":        "颜色...",
+		"Spotlight":         "演示聚光灯",
+		"SpotlightEnabled":  "调暗光标周围以外的屏幕",
+		"SpotlightShape":    "形状:",
+		"SpotlightCircle":   "圆形",
+		"SpotlightRect":     "矩形",
+		"SpotlightRadius":   "半径:",
+		"SpotlightWidth":    "宽度:",
+		"SpotlightHeight":   "高度:",
+		"SpotlightFeather":  "边缘柔化 (像素):",
+		"SpotlightDim":      "遮罩浓度:",
+		"MenuSpotlight":     "聚光灯",
+		"TabPresenter":      "演示",
+		"Ink":               "屏幕批注",
+		"InkEnabled":        "按住热键时绘制",
+		"InkHotkey":         "热键 (修饰键):",
+		"InkTool":           "工具:",
+		"InkPen":            "钢笔",
+		"InkHighlighter":    "荧光笔",
+		"InkArrow":          "箭头",
+		"InkRect":           "矩形",
+		"InkEllipse":        "椭圆",
+		"InkWidth":          "宽度:",
+		"InkFadeAfter":      "自动淡出 (秒，0 = 保留):",
+		"InkColor":          "颜色...",
+		"InkHint":           "撤销和清除在托盘菜单中。",
+		"MenuInkUndo":       "撤销批注",
+		"MenuInkClear":      "清除批注",
+		"Laser":             "激光笔",
+		"LaserEnabled":      "激光笔模式",
+		"LaserWidth":        "光束宽度:",
+		"LaserDot":          "光点半径:",
+		"LaserAutoOff":      "静止多久后恢复 (秒，0 = 不恢复):",
+		"LaserHide":         "隐藏系统光标",
+		"LaserColor":        "颜色...",
+		"MenuLaser":         "激光笔",
+		"MenuPause":         "暂停效果",
+		"TabHotkeys":        "热键",
+		"Hotkeys":           "全局热键",
+		"HotkeyPause":       "暂停 / 恢复:",
+		"HotkeyRipples":     "开关点击波纹:",
+		"HotkeyPreset":      "下一个图层预设:",
+		"HotkeySpotlight":   "开关聚光灯:",
+		"HotkeyLaser":       "开关激光笔:",
+		"HotkeyInkUndo":     "撤销批注:",
+		"HotkeyInkClear":    "清除批注:",
+		"HotkeyConfig":      "打开设置:",
+		"HotkeysHint":       "如 Ctrl+Alt+P，留空表示不使用。关闭本窗口后生效。",
+		"HotkeyErrors":      "以下热键无法使用:",
 	},
 }
 
@@ -84,13 +419,7 @@ func SetLanguage(lang string) {
 		currentLang = LangEnglish
 	default: // "auto" or others
 		// 检测系统语言
-		langID, _, _ := procGetUserDefaultUILanguage.Call()
-		// 0x0804 是简体中文 (2052)
-		if langID == 0x0804 {
-			currentLang = LangChinese
-		} else {
-			currentLang = LangEnglish
-		}
+		currentLang = systemLanguage()
 	}
 }
 
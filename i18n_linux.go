@@ -0,0 +1,19 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// systemLanguage 按 POSIX 约定从 LC_ALL / LC_MESSAGES / LANG 检测系统语言
+func systemLanguage() Language {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			if strings.HasPrefix(v, "zh") {
+				return LangChinese
+			}
+			return LangEnglish
+		}
+	}
+	return LangEnglish
+}
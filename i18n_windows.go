@@ -0,0 +1,20 @@
+package main
+
+import (
+	"syscall"
+)
+
+var (
+	kernel32dll                  = syscall.NewLazyDLL("kernel32.dll")
+	procGetUserDefaultUILanguage = kernel32dll.NewProc("GetUserDefaultUILanguage")
+)
+
+// systemLanguage 通过 GetUserDefaultUILanguage 检测系统语言
+func systemLanguage() Language {
+	langID, _, _ := procGetUserDefaultUILanguage.Call()
+	// 0x0804 是简体中文 (2052)
+	if langID == 0x0804 {
+		return LangChinese
+	}
+	return LangEnglish
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"image"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 批注工具
+const (
+	InkPen         = "pen"         // 自由笔画
+	InkHighlighter = "highlighter" // 宽而半透明的自由笔画
+	InkArrow       = "arrow"       // 从起点指向终点的箭头
+	InkRect        = "rect"        // 以起点和终点为对角的矩形
+	InkEllipse     = "ellipse"     // 内切于起点和终点矩形的椭圆
+)
+
+const (
+	// 自由笔画相邻采样点的最小间距 (像素)
+	inkSpacing = 3.0
+	// 所有笔画的折线点总数上限，超出时删除最早的笔画，限制每帧的三角化开销
+	maxInkPoints = 3000
+	// 荧光笔相对笔宽的倍数和透明度系数
+	inkHighlighterScale = 4.0
+	inkHighlighterAlpha = 0.4
+	// 开始自动淡出后完全消失需要的时间 (秒)
+	inkFadeDuration = 0.5
+	// 椭圆的边数
+	inkEllipseSegments = 64
+)
+
+// InkConfig 屏幕批注设置
+// 按住 Hotkey 指定的修饰键组合时，鼠标移动留下的笔迹会保留在屏幕上
+type InkConfig struct {
+	Enabled   bool     `json:"enabled"`
+	Hotkey    string   `json:"hotkey"`     // 按住时绘制的修饰键组合，如 "Ctrl+Shift"
+	Tool      string   `json:"tool"`       // "pen", "highlighter", "arrow", "rect", "ellipse"
+	Color     [4]uint8 `json:"color"`      // RGBA
+	Width     float64  `json:"width"`      // 笔画宽度 (像素)
+	FadeAfter float64  `json:"fade_after"` // 笔画完成后多少秒开始淡出，0 表示一直保留
+}
+
+func defaultInkConfig() InkConfig {
+	return InkConfig{
+		Enabled: false,
+		Hotkey:  "Ctrl+Shift",
+		Tool:    InkPen,
+		Color:   [4]uint8{255, 40, 40, 255},
+		Width:   4,
+	}
+}
+
+// inkStroke 一条笔画，工具、颜色和宽度在开始绘制时确定
+// 自由笔画保存所有采样点，图形只保存起点和终点
+type inkStroke struct {
+	Tool   string
+	Color  [4]uint8
+	Width  float64
+	Points []TracePoint
+	Done   time.Time // 完成时间，绘制中为零值
+	Life   float64   // 1.0 -> 0.0，自动淡出时减少
+}
+
+// inkState 批注笔画和每帧复用的缓冲
+// 批注与轨迹分开绘制，使用自己的顶点和索引，不计入轨迹的顶点数
+type inkState struct {
+	strokes []inkStroke
+	drawing bool // 是否正在绘制最后一条笔画
+	held    bool // 本帧是否按住了绘制热键
+	blocked bool // 按住热键时按下了其他键，松开热键前不再绘制
+
+	vertices []ebiten.Vertex
+	indices  []uint32
+	pts      []TracePoint
+	widths   []float64
+	colors   []vertexColor
+	arc      []float64
+}
+
+// SetInking 设置是否按住了绘制热键，每帧调用
+func (tm *TraceManager) SetInking(held bool) {
+	tm.ink.held = held
+	if !held {
+		tm.ink.blocked = false
+	}
+}
+
+// CancelInkStroke 删除正在绘制的笔画，松开绘制热键前不再开始新的笔画
+// 用于按住热键的同时按下了其他键，即热键只是快捷键的一部分
+func (tm *TraceManager) CancelInkStroke() {
+	s := &tm.ink
+	if s.drawing {
+		s.strokes = s.strokes[:len(s.strokes)-1]
+		s.drawing = false
+	}
+	s.blocked = true
+}
+
+// UndoInk 删除最后一条笔画
+func (tm *TraceManager) UndoInk() {
+	s := &tm.ink
+	if len(s.strokes) == 0 {
+		return
+	}
+	s.strokes = s.strokes[:len(s.strokes)-1]
+	s.drawing = false
+}
+
+// ClearInk 删除所有笔画
+func (tm *TraceManager) ClearInk() {
+	tm.ink.strokes = tm.ink.strokes[:0]
+	tm.ink.drawing = false
+}
+
+// updateInk 按热键状态开始、延长或结束笔画，并推进自动淡出
+// 返回是否正在绘制或有笔画在淡出
+func (tm *TraceManager) updateInk(x, y float64, now time.Time, dt float64) bool {
+	s := &tm.ink
+	cfg := &tm.config.Ink
+	held := s.held && cfg.Enabled && !s.blocked
+	p := TracePoint{X: x, Y: y, Life: 1, Time: now}
+
+	switch {
+	case held && !s.drawing:
+		s.strokes = append(s.strokes, inkStroke{
+			Tool:   cfg.Tool,
+			Color:  cfg.Color,
+			Width:  math.Max(1, cfg.Width),
+			Points: []TracePoint{p},
+			Life:   1,
+		})
+		s.drawing = true
+	case held:
+		stroke := &s.strokes[len(s.strokes)-1]
+		last := stroke.Points[len(stroke.Points)-1]
+		switch {
+		case stroke.Tool != InkPen && stroke.Tool != InkHighlighter:
+			// 图形只保留起点和当前位置
+			stroke.Points = append(stroke.Points[:1], p)
+		case math.Hypot(x-last.X, y-last.Y) >= inkSpacing:
+			stroke.Points = append(stroke.Points, p)
+		}
+	case s.drawing:
+		s.strokes[len(s.strokes)-1].Done = now
+		s.drawing = false
+	}
+
+	tm.limitInk()
+
+	// 自动淡出
+	fading := false
+	n := 0
+	for i := range s.strokes {
+		stroke := &s.strokes[i]
+		if cfg.FadeAfter > 0 && !stroke.Done.IsZero() && elapsedSeconds(stroke.Done, now) > cfg.FadeAfter {
+			stroke.Life -= dt / inkFadeDuration
+			fading = true
+		}
+		if stroke.Life > 0 {
+			s.strokes[n] = *stroke
+			n++
+		}
+	}
+	s.strokes = s.strokes[:n]
+	return s.drawing || fading
+}
+
+// points 返回笔画生成的折线点数
+func (s *inkStroke) points() int {
+	switch s.Tool {
+	case InkArrow:
+		return 2
+	case InkRect:
+		return 5
+	case InkEllipse:
+		return inkEllipseSegments + 1
+	}
+	return len(s.Points)
+}
+
+// limitInk 折线点总数超过 maxInkPoints 时删除最早的笔画 (最后一条笔画除外)
+func (tm *TraceManager) limitInk() {
+	s := &tm.ink
+	total := 0
+	for i := range s.strokes {
+		total += s.strokes[i].points()
+	}
+	drop := 0
+	for total > maxInkPoints && drop < len(s.strokes)-1 {
+		total -= s.strokes[drop].points()
+		drop++
+	}
+	s.strokes = append(s.strokes[:0], s.strokes[drop:]...)
+}
+
+// buildInk 生成所有笔画的三角形到 tm.ink.vertices / tm.ink.indices
+// 借用轨迹的 addPolyline 等三角化函数：临时把 tm.vertices / tm.indices 换成批注的缓冲
+func (tm *TraceManager) buildInk() bool {
+	s := &tm.ink
+	tm.vertices, s.vertices = s.vertices[:0], tm.vertices
+	tm.indices, s.indices = s.indices[:0], tm.indices
+	for i := range s.strokes {
+		tm.buildInkStroke(&s.strokes[i])
+	}
+	tm.vertices, s.vertices = s.vertices, tm.vertices
+	tm.indices, s.indices = s.indices, tm.indices
+	return len(s.indices) > 0
+}
+
+func (tm *TraceManager) buildInkStroke(stroke *inkStroke) {
+	s := &tm.ink
+	half := stroke.Width / 2
+	alpha := stroke.Life
+	if stroke.Tool == InkHighlighter {
+		half *= inkHighlighterScale
+		alpha *= inkHighlighterAlpha
+	}
+	c := tm.styleColor(stroke.Color, alpha)
+
+	start := stroke.Points[0]
+	end := stroke.Points[len(stroke.Points)-1]
+	pts := s.pts[:0]
+	switch stroke.Tool {
+	case InkArrow:
+		pts = tm.inkArrow(pts, start, end, half, c)
+	case InkRect:
+		pts = append(pts,
+			TracePoint{X: start.X, Y: start.Y},
+			TracePoint{X: end.X, Y: start.Y},
+			TracePoint{X: end.X, Y: end.Y},
+			TracePoint{X: start.X, Y: end.Y},
+			TracePoint{X: start.X, Y: start.Y},
+		)
+	case InkEllipse:
+		cx, cy := (start.X+end.X)/2, (start.Y+end.Y)/2
+		rx, ry := math.Abs(end.X-start.X)/2, math.Abs(end.Y-start.Y)/2
+		for i := 0; i <= inkEllipseSegments; i++ {
+			sin, cos := math.Sincos(float64(i) * 2 * math.Pi / inkEllipseSegments)
+			pts = append(pts, TracePoint{X: cx + rx*cos, Y: cy + ry*sin})
+		}
+	default:
+		// 采样间距已经足够小，不再平滑，保证点数不超过 maxInkPoints
+		pts = append(pts, stroke.Points...)
+	}
+	s.pts = pts
+
+	s.widths, s.colors, s.arc = s.widths[:0], s.colors[:0], s.arc[:0]
+	for range pts {
+		s.widths = append(s.widths, half)
+		s.colors = append(s.colors, c)
+		s.arc = append(s.arc, 0)
+	}
+	tm.addPolyline(pts, s.widths, s.colors, s.arc)
+}
+
+// inkArrow 添加箭头的实心三角形，返回箭杆的两个端点
+// 箭头长度随笔宽变化，箭杆短于箭头时只画箭头
+func (tm *TraceManager) inkArrow(pts []TracePoint, start, end TracePoint, half float64, c vertexColor) []TracePoint {
+	dx, dy := end.X-start.X, end.Y-start.Y
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return append(pts, start)
+	}
+	ux, uy := dx/l, dy/l
+	headLen := math.Max(18, half*6)
+	headHalf := headLen * 0.5
+
+	// 箭头底边的中点，箭杆画到这里
+	bx, by := end.X-ux*math.Min(headLen, l), end.Y-uy*math.Min(headLen, l)
+	idx := uint32(len(tm.vertices))
+	tm.vertices = append(tm.vertices,
+		ebitenVertex(end.X, end.Y, c),
+		ebitenVertex(bx-uy*headHalf, by+ux*headHalf, c),
+		ebitenVertex(bx+uy*headHalf, by-ux*headHalf, c),
+	)
+	tm.indices = append(tm.indices, idx, idx+1, idx+2)
+
+	if l <= headLen {
+		return pts
+	}
+	return append(pts, start, TracePoint{X: bx, Y: by})
+}
+
+// drawInk 把批注绘制到 screen，在聚光灯遮罩之上、轨迹之下
+func (tm *TraceManager) drawInk(screen *ebiten.Image) {
+	if !tm.buildInk() {
+		return
+	}
+	screen.DrawTriangles32(tm.ink.vertices, tm.ink.indices, tm.whiteImage, &ebiten.DrawTrianglesOptions{
+		Blend: maxBlend,
+	})
+}
+
+// drawInkRGBA 软件渲染路径的 drawInk
+func (tm *TraceManager) drawInkRGBA(dst *image.RGBA) {
+	if !tm.buildInk() {
+		return
+	}
+	RasterizeTriangles(dst, tm.ink.vertices, tm.ink.indices)
+}
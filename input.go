@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MouseButton 鼠标按键
+type MouseButton int
+
+const (
+	MouseButtonLeft MouseButton = iota
+	MouseButtonRight
+	MouseButtonMiddle
+	MouseButtonX1
+	MouseButtonX2
+
+	mouseButtonCount
+)
+
+// InputState 一次采样得到的输入状态
+// 坐标已经换算到覆盖窗口的 Layout 坐标系
+type InputState struct {
+	X, Y    int
+	Buttons [mouseButtonCount]bool
+	// 自上次采样以来累计的滚轮增量，单位为"格" (120 = 1 格)
+	// WheelY 向上滚动为正，WheelX 向右为正
+	WheelX, WheelY float64
+	// 当前按住的修饰键，以及自上次采样以来按下的其他键
+	Mods Modifier
+	Keys []KeyEvent
+	Time time.Time
+}
+
+// Modifier 修饰键掩码
+type Modifier uint8
+
+const (
+	ModCtrl Modifier = 1 << iota
+	ModAlt
+	ModShift
+	ModSuper
+)
+
+// modifierNames 修饰键的显示顺序和名称
+var modifierNames = []struct {
+	mod  Modifier
+	name string
+}{
+	{ModCtrl, "Ctrl"},
+	{ModAlt, "Alt"},
+	{ModShift, "Shift"},
+	{ModSuper, superKeyName},
+}
+
+// String 返回 "Ctrl+Shift" 形式的名称
+func (m Modifier) String() string {
+	var sb strings.Builder
+	for _, n := range modifierNames {
+		if m&n.mod == 0 {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("+")
+		}
+		sb.WriteString(n.name)
+	}
+	return sb.String()
+}
+
+// ParseModifiers 解析 "Ctrl+Shift" 形式的修饰键组合，不区分大小写
+// 除 String 输出的名称外还接受 Control、Win、Super、Cmd 等别名
+func ParseModifiers(s string) (Modifier, error) {
+	var m Modifier
+	for _, part := range strings.Split(s, "+") {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "ctrl", "control":
+			m |= ModCtrl
+		case "alt":
+			m |= ModAlt
+		case "shift":
+			m |= ModShift
+		case "win", "super", "meta", "cmd":
+			m |= ModSuper
+		default:
+			return 0, fmt.Errorf("unknown modifier %q in %q", part, s)
+		}
+	}
+	return m, nil
+}
+
+// KeyEvent 一次按键，不包括单独按下的修饰键
+type KeyEvent struct {
+	Key       string   // 显示名称，如 "S"、"Enter"、"F5"
+	Mods      Modifier // 按下时按住的修饰键
+	Secret    bool     // 按下时输入焦点在密码框中
+	Printable bool     // 字母、数字和标点等输入字符的键，由平台按键码判断
+}
+
+// Pressed 返回指定按键是否处于按下状态
+func (s InputState) Pressed(b MouseButton) bool {
+	if b < 0 || b >= mouseButtonCount {
+		return false
+	}
+	return s.Buttons[b]
+}
+
+// InputSource 输入源
+// 把光标、按键、滚轮的读取从具体平台 API 中剥离出来，
+// 这样 TraceManager 和波纹逻辑可以脱离 user32.dll 运行
+type InputSource interface {
+	// Poll 采样当前输入状态，每次 Update 调用一次
+	Poll() InputState
+}
+
+// ScriptedInput 脚本化输入源，按顺序回放预先录入的状态
+// 用于测试和演示；脚本播放完后保持最后一个状态 (滚轮增量和按键事件清零)
+type ScriptedInput struct {
+	frames []InputState
+	pos    int
+	now    time.Time
+
+	// 未指定 Time 的帧按 Start + 序号*Interval 生成时间戳
+	Start    time.Time
+	Interval time.Duration
+}
+
+// NewScriptedInput 创建脚本化输入源，默认按 60 TPS 生成时间戳
+func NewScriptedInput(frames ...InputState) *ScriptedInput {
+	return &ScriptedInput{
+		frames:   frames,
+		Start:    time.Unix(0, 0),
+		Interval: time.Second / 60,
+	}
+}
+
+// Append 追加若干帧
+func (s *ScriptedInput) Append(frames ...InputState) {
+	s.frames = append(s.frames, frames...)
+}
+
+// MoveTo 追加一段从当前位置到 (x, y) 的直线移动，共 steps 帧，期间保持按键状态
+func (s *ScriptedInput) MoveTo(x, y, steps int) {
+	last := s.next()
+	if steps < 1 {
+		steps = 1
+	}
+	for i := 1; i <= steps; i++ {
+		f := last
+		f.X = last.X + (x-last.X)*i/steps
+		f.Y = last.Y + (y-last.Y)*i/steps
+		s.frames = append(s.frames, f)
+	}
+}
+
+// Press 追加一帧，按下指定按键
+func (s *ScriptedInput) Press(b MouseButton) {
+	s.setButton(b, true)
+}
+
+// Release 追加一帧，松开指定按键
+func (s *ScriptedInput) Release(b MouseButton) {
+	s.setButton(b, false)
+}
+
+// Click 追加按下、松开两帧
+func (s *ScriptedInput) Click(b MouseButton) {
+	s.Press(b)
+	s.Release(b)
+}
+
+// Hold 追加一帧，之后的帧都按住 mods 指定的修饰键
+func (s *ScriptedInput) Hold(mods Modifier) {
+	f := s.next()
+	f.Mods = mods
+	s.frames = append(s.frames, f)
+}
+
+// Wheel 追加一帧滚轮事件
+func (s *ScriptedInput) Wheel(dx, dy float64) {
+	f := s.next()
+	f.WheelX, f.WheelY = dx, dy
+	s.frames = append(s.frames, f)
+}
+
+// Key 追加一帧按键事件，按键时按住 mods，之后修饰键保持按住状态
+func (s *ScriptedInput) Key(key string, mods Modifier) {
+	s.key(KeyEvent{Key: key, Mods: mods})
+}
+
+// Type 追加一帧输入字符的按键事件，key 为字母、数字或标点的显示名称
+func (s *ScriptedInput) Type(key string, mods Modifier) {
+	s.key(KeyEvent{Key: key, Mods: mods, Printable: true})
+}
+
+func (s *ScriptedInput) key(ev KeyEvent) {
+	f := s.next()
+	f.Mods = ev.Mods
+	f.Keys = []KeyEvent{ev}
+	s.frames = append(s.frames, f)
+}
+
+// Wait 追加 n 帧保持不动
+func (s *ScriptedInput) Wait(n int) {
+	f := s.next()
+	for i := 0; i < n; i++ {
+		s.frames = append(s.frames, f)
+	}
+}
+
+// Done 返回脚本是否已经播放完毕
+func (s *ScriptedInput) Done() bool {
+	return s.pos >= len(s.frames)
+}
+
+// Poll 实现 InputSource
+func (s *ScriptedInput) Poll() InputState {
+	idx := s.pos
+	var f InputState
+	if idx >= len(s.frames) {
+		// 播放完毕后保持最后一帧，但不重复滚轮增量和按键事件
+		f = s.next()
+	} else {
+		f = s.frames[idx]
+	}
+	if f.Time.IsZero() {
+		f.Time = s.Start.Add(time.Duration(idx) * s.Interval)
+	}
+	s.pos++
+	s.now = f.Time
+	return f
+}
+
+// Now 实现 Clock，返回最近一次 Poll 的时间戳
+// 把脚本同时作为 TraceManager 的时钟，动画就完全由脚本时间驱动
+func (s *ScriptedInput) Now() time.Time {
+	if s.now.IsZero() {
+		return s.Start
+	}
+	return s.now
+}
+
+func (s *ScriptedInput) setButton(b MouseButton, down bool) {
+	if b < 0 || b >= mouseButtonCount {
+		return
+	}
+	f := s.next()
+	f.Buttons[b] = down
+	s.frames = append(s.frames, f)
+}
+
+func (s *ScriptedInput) last() InputState {
+	if len(s.frames) == 0 {
+		return InputState{}
+	}
+	return s.frames[len(s.frames)-1]
+}
+
+// next 返回以最后一帧为基础的新帧，清除只在单帧内有效的滚轮增量、按键事件和时间戳
+func (s *ScriptedInput) next() InputState {
+	f := s.last()
+	f.WheelX, f.WheelY = 0, 0
+	f.Keys = nil
+	f.Time = time.Time{}
+	return f
+}
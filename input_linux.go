@@ -0,0 +1,106 @@
+package main
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// x11Input 基于 XQueryPointer 轮询的输入源
+// QueryPointer 的按键掩码只包含按钮 1-5，且滚轮 (4/5) 只是瞬时状态，
+// 因此这里只报告左、中、右键，滚轮和侧键保持为零。按键通过 QueryKeymap 轮询
+type x11Input struct {
+	conn         *xgb.Conn
+	root         xproto.Window
+	screenWidth  int
+	screenHeight int
+
+	// 覆盖窗口，由 x11Overlay 找到后设置
+	window atomic.Uint32
+
+	// 缓存窗口大小，避免频繁调用 GetGeometry
+	windowWidth, windowHeight int
+	geometryTimer             int
+
+	// 键盘状态，获取键盘映射失败时为 nil
+	keyboard *x11Keyboard
+	// 是否读取键盘，由 SetKeyboardCapture 设置
+	captureKeys bool
+}
+
+func newX11Input(conn *xgb.Conn, root xproto.Window, screenWidth, screenHeight int) *x11Input {
+	return &x11Input{
+		conn:         conn,
+		root:         root,
+		screenWidth:  screenWidth,
+		screenHeight: screenHeight,
+		keyboard:     newX11Keyboard(conn),
+	}
+}
+
+func (in *x11Input) setWindow(w xproto.Window) {
+	in.window.Store(uint32(w))
+}
+
+// Poll 实现 InputSource
+func (in *x11Input) Poll() InputState {
+	state := InputState{Time: time.Now()}
+
+	target := xproto.Window(in.window.Load())
+	if target == 0 {
+		target = in.root
+	}
+
+	reply, err := xproto.QueryPointer(in.conn, target).Reply()
+	if err != nil {
+		return state
+	}
+
+	state.Buttons[MouseButtonLeft] = reply.Mask&xproto.KeyButMaskButton1 != 0
+	state.Buttons[MouseButtonMiddle] = reply.Mask&xproto.KeyButMaskButton2 != 0
+	state.Buttons[MouseButtonRight] = reply.Mask&xproto.KeyButMaskButton3 != 0
+
+	if reply.Mask&xproto.KeyButMaskControl != 0 {
+		state.Mods |= ModCtrl
+	}
+	if reply.Mask&xproto.KeyButMaskMod1 != 0 {
+		state.Mods |= ModAlt
+	}
+	if reply.Mask&xproto.KeyButMaskShift != 0 {
+		state.Mods |= ModShift
+	}
+	if reply.Mask&xproto.KeyButMaskMod4 != 0 {
+		state.Mods |= ModSuper
+	}
+	if in.keyboard != nil && in.captureKeys {
+		state.Keys = in.keyboard.poll(in.conn, state.Mods)
+	}
+
+	if target == in.root || !reply.SameScreen {
+		state.X, state.Y = int(reply.RootX), int(reply.RootY)
+		return state
+	}
+
+	// 缓存窗口大小，每 60 帧更新一次 (约 1 秒)
+	in.geometryTimer++
+	if in.geometryTimer > 60 || in.windowWidth == 0 {
+		if geom, err := xproto.GetGeometry(in.conn, xproto.Drawable(target)).Reply(); err == nil {
+			in.windowWidth = int(geom.Width)
+			in.windowHeight = int(geom.Height)
+		}
+		in.geometryTimer = 0
+	}
+
+	// 避免除以 0
+	if in.windowWidth <= 0 || in.windowHeight <= 0 {
+		state.X, state.Y = int(reply.WinX), int(reply.WinY)
+		return state
+	}
+
+	// 按比例映射到 Ebiten 的 Layout 坐标系，兼容 HiDPI 缩放
+	state.X = int(float64(reply.WinX) / float64(in.windowWidth) * float64(in.screenWidth))
+	state.Y = int(float64(reply.WinY) / float64(in.windowHeight) * float64(in.screenHeight))
+	return state
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/lxn/win"
+)
+
+const (
+	VK_LBUTTON  = 0x01
+	VK_RBUTTON  = 0x02
+	VK_MBUTTON  = 0x04
+	VK_XBUTTON1 = 0x05
+	VK_XBUTTON2 = 0x06
+)
+
+// 与 MouseButton 一一对应的虚拟键码
+var mouseButtonVKs = [mouseButtonCount]uintptr{
+	MouseButtonLeft:   VK_LBUTTON,
+	MouseButtonRight:  VK_RBUTTON,
+	MouseButtonMiddle: VK_MBUTTON,
+	MouseButtonX1:     VK_XBUTTON1,
+	MouseButtonX2:     VK_XBUTTON2,
+}
+
+// isKeyPressed 使用 GetAsyncKeyState 检测按键状态
+// 这可以绕过 WS_EX_TRANSPARENT 导致的 Ebiten 无法接收鼠标事件的问题
+func isKeyPressed(vk uintptr) bool {
+	ret, _, _ := procGetAsyncKeyState.Call(vk)
+	// 如果最高位被设置 (0x8000)，则表示键被按下
+	return (ret & 0x8000) != 0
+}
+
+// win32Input 基于 Win32 轮询的输入源
+type win32Input struct {
+	screenWidth  int
+	screenHeight int
+
+	// 覆盖窗口句柄，由游戏线程和 win32Overlay 的协程找到后设置
+	hwnd atomic.Uintptr
+
+	// 缓存窗口位置，避免频繁调用 GetWindowRect
+	cachedWindowRect win.RECT
+	rectUpdateTimer  int
+
+	// 低级钩子，提供滚轮和按键等瞬时事件
+	hooks lowLevelHooks
+}
+
+func newWin32Input(screenWidth, screenHeight int) *win32Input {
+	return &win32Input{
+		screenWidth:  screenWidth,
+		screenHeight: screenHeight,
+	}
+}
+
+func (in *win32Input) setWindow(hwnd win.HWND) {
+	in.hwnd.Store(uintptr(hwnd))
+}
+
+// window 返回覆盖窗口句柄，还没有找到时为 0
+func (in *win32Input) window() win.HWND {
+	return win.HWND(in.hwnd.Load())
+}
+
+// Poll 实现 InputSource
+func (in *win32Input) Poll() InputState {
+	state := InputState{Time: time.Now()}
+
+	// 获取鼠标位置
+	var pt win.POINT
+	win.GetCursorPos(&pt)
+	state.X, state.Y = in.toWindow(pt)
+
+	for b, vk := range mouseButtonVKs {
+		state.Buttons[b] = isKeyPressed(vk)
+	}
+	state.WheelX, state.WheelY = in.hooks.takeWheel()
+	state.Mods = currentModifiers()
+	state.Keys = in.hooks.takeKeys()
+
+	return state
+}
+
+// toWindow 计算相对于窗口的坐标
+// 如果找到了窗口句柄，就用真实的窗口位置计算
+// 否则回退到虚拟屏幕计算（虽然这可能是错的）
+func (in *win32Input) toWindow(pt win.POINT) (int, int) {
+	hwnd := in.window()
+	if hwnd == 0 {
+		// 尝试查找窗口句柄 (如果 main 中的协程还没找到)
+		// 注意：频繁 FindWindow 可能有开销，但这里只有在找不到时才调用
+		titlePtr := syscall.StringToUTF16Ptr(overlayTitle)
+		hwnd = win.FindWindow(nil, titlePtr)
+		if hwnd == 0 {
+			// 实在找不到，暂时使用虚拟屏幕原点
+			x := win.GetSystemMetrics(win.SM_XVIRTUALSCREEN)
+			y := win.GetSystemMetrics(win.SM_YVIRTUALSCREEN)
+			return int(pt.X) - int(x), int(pt.Y) - int(y)
+		}
+		in.setWindow(hwnd)
+		in.rectUpdateTimer = 0
+		in.cachedWindowRect = win.RECT{}
+	}
+
+	// 缓存窗口位置，每 60 帧更新一次 (约 1 秒)
+	in.rectUpdateTimer++
+	if in.rectUpdateTimer > 60 || in.cachedWindowRect.Right == 0 {
+		win.GetWindowRect(hwnd, &in.cachedWindowRect)
+		in.rectUpdateTimer = 0
+	}
+	rect := in.cachedWindowRect
+
+	// 使用比例映射来解决 DPI 缩放导致的不一致问题
+	// 窗口的物理像素大小
+	windowWidth := int(rect.Right - rect.Left)
+	windowHeight := int(rect.Bottom - rect.Top)
+
+	// 避免除以 0
+	if windowWidth <= 0 || windowHeight <= 0 {
+		// 如果窗口大小异常，回退到简单差值
+		return int(pt.X) - int(rect.Left), int(pt.Y) - int(rect.Top)
+	}
+
+	// 计算鼠标相对于窗口左上角的偏移量
+	offsetX := int(pt.X) - int(rect.Left)
+	offsetY := int(pt.Y) - int(rect.Top)
+
+	// 计算归一化比例 (0.0 - 1.0)
+	rx := float64(offsetX) / float64(windowWidth)
+	ry := float64(offsetY) / float64(windowHeight)
+
+	// 映射到 Ebiten 的 Layout 坐标系
+	return int(rx * float64(in.screenWidth)), int(ry * float64(in.screenHeight))
+}
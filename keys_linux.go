@@ -0,0 +1,114 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// superKeyName Super 键的显示名称
+const superKeyName = "Super"
+
+// keysymNames 非字母数字键的显示名称
+var keysymNames = map[xproto.Keysym]string{
+	0xff08: "Backspace",
+	0xff09: "Tab",
+	0xff0d: "Enter",
+	0xff13: "Pause",
+	0xff14: "ScrollLock",
+	0xff1b: "Esc",
+	0xff50: "Home",
+	0xff51: "←",
+	0xff52: "↑",
+	0xff53: "→",
+	0xff54: "↓",
+	0xff55: "PgUp",
+	0xff56: "PgDn",
+	0xff57: "End",
+	0xff61: "PrtSc",
+	0xff63: "Ins",
+	0xff67: "Menu",
+	0xff7f: "NumLock",
+	0xff8d: "NumEnter",
+	0xffaa: "Num*",
+	0xffab: "Num+",
+	0xffad: "Num-",
+	0xffae: "Num.",
+	0xffaf: "Num/",
+	0xffe5: "CapsLock",
+	0xffff: "Del",
+	0x0020: "Space",
+}
+
+// keysymName 返回 keysym 的显示名称，修饰键和未知按键返回空字符串
+func keysymName(sym xproto.Keysym) string {
+	switch {
+	case sym >= 'a' && sym <= 'z':
+		return string(rune(sym - 'a' + 'A'))
+	case sym > 0x20 && sym < 0x7f: // 其余可打印 ASCII
+		return string(rune(sym))
+	case sym >= 0xffb0 && sym <= 0xffb9: // 小键盘数字
+		return "Num" + string(rune('0'+sym-0xffb0))
+	case sym >= 0xffbe && sym <= 0xffd5: // F1 - F24
+		return "F" + strconv.Itoa(int(sym-0xffbe+1))
+	}
+	return keysymNames[sym]
+}
+
+// x11Keyboard 通过 QueryKeymap 轮询键盘状态，比较前后两次的差异得到按下的键
+// 轮询间隔内按下又松开的键会漏掉，这是不安装全局键盘抓取的代价
+type x11Keyboard struct {
+	minKeycode xproto.Keycode
+	perKeycode int
+	keysyms    []xproto.Keysym
+	prev       [32]byte
+}
+
+// newX11Keyboard 读取键码到 keysym 的映射，失败时返回 nil
+func newX11Keyboard(conn *xgb.Conn) *x11Keyboard {
+	setup := xproto.Setup(conn)
+	count := byte(setup.MaxKeycode - setup.MinKeycode + 1)
+	reply, err := xproto.GetKeyboardMapping(conn, setup.MinKeycode, count).Reply()
+	if err != nil || reply.KeysymsPerKeycode == 0 {
+		return nil
+	}
+	return &x11Keyboard{
+		minKeycode: setup.MinKeycode,
+		perKeycode: int(reply.KeysymsPerKeycode),
+		keysyms:    reply.Keysyms,
+	}
+}
+
+// poll 返回自上次调用以来新按下的键
+func (k *x11Keyboard) poll(conn *xgb.Conn, mods Modifier) []KeyEvent {
+	reply, err := xproto.QueryKeymap(conn).Reply()
+	if err != nil || len(reply.Keys) < len(k.prev) {
+		return nil
+	}
+
+	var keys []KeyEvent
+	for i := range k.prev {
+		pressed := reply.Keys[i] &^ k.prev[i]
+		k.prev[i] = reply.Keys[i]
+		for bit := 0; pressed != 0 && bit < 8; bit++ {
+			if pressed&(1<<bit) == 0 {
+				continue
+			}
+			sym := k.keysym(xproto.Keycode(i*8 + bit))
+			if name := keysymName(sym); name != "" {
+				keys = append(keys, KeyEvent{Key: name, Mods: mods, Printable: sym > 0x20 && sym < 0x7f})
+			}
+		}
+	}
+	return keys
+}
+
+// keysym 返回键码的第一个 (不带 Shift 的) keysym
+func (k *x11Keyboard) keysym(code xproto.Keycode) xproto.Keysym {
+	idx := (int(code) - int(k.minKeycode)) * k.perKeycode
+	if code < k.minKeycode || idx >= len(k.keysyms) {
+		return 0
+	}
+	return k.keysyms[idx]
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+// superKeyName Windows 键的显示名称
+const superKeyName = "Win"
+
+const (
+	VK_SHIFT   = 0x10
+	VK_CONTROL = 0x11
+	VK_MENU    = 0x12
+	VK_LWIN    = 0x5B
+	VK_RWIN    = 0x5C
+)
+
+var procGetGUIThreadInfo = user32dll.NewProc("GetGUIThreadInfo")
+
+// GUITHREADINFO 前台线程的焦点信息
+type GUITHREADINFO struct {
+	CbSize        uint32
+	Flags         uint32
+	HwndActive    win.HWND
+	HwndFocus     win.HWND
+	HwndCapture   win.HWND
+	HwndMenuOwner win.HWND
+	HwndMoveSize  win.HWND
+	HwndCaret     win.HWND
+	RcCaret       win.RECT
+}
+
+// vkNames 非字母数字键的显示名称
+var vkNames = map[uint32]string{
+	0x08: "Backspace",
+	0x09: "Tab",
+	0x0D: "Enter",
+	0x13: "Pause",
+	0x14: "CapsLock",
+	0x1B: "Esc",
+	0x20: "Space",
+	0x21: "PgUp",
+	0x22: "PgDn",
+	0x23: "End",
+	0x24: "Home",
+	0x25: "←",
+	0x26: "↑",
+	0x27: "→",
+	0x28: "↓",
+	0x2C: "PrtSc",
+	0x2D: "Ins",
+	0x2E: "Del",
+	0x5D: "Menu",
+	0x6A: "Num*",
+	0x6B: "Num+",
+	0x6D: "Num-",
+	0x6E: "Num.",
+	0x6F: "Num/",
+	0x90: "NumLock",
+	0x91: "ScrollLock",
+	0xBA: ";",
+	0xBB: "=",
+	0xBC: ",",
+	0xBD: "-",
+	0xBE: ".",
+	0xBF: "/",
+	0xC0: "`",
+	0xDB: "[",
+	0xDC: "\\",
+	0xDD: "]",
+	0xDE: "'",
+}
+
+// keyName 返回虚拟键码的显示名称，修饰键和未知按键返回空字符串
+func keyName(vk uint32) string {
+	switch {
+	case vk >= '0' && vk <= '9', vk >= 'A' && vk <= 'Z':
+		return string(rune(vk))
+	case vk >= 0x60 && vk <= 0x69: // 小键盘数字
+		return "Num" + string(rune('0'+vk-0x60))
+	case vk >= 0x70 && vk <= 0x87: // F1 - F24
+		return "F" + strconv.Itoa(int(vk-0x70+1))
+	}
+	return vkNames[vk]
+}
+
+// isPrintableVK 返回虚拟键码是否为输入字符的键：字母、数字和 OEM 标点键
+// 方向键、小键盘等即使显示名称只有一个字符也不算
+func isPrintableVK(vk uint32) bool {
+	switch {
+	case vk >= '0' && vk <= '9', vk >= 'A' && vk <= 'Z':
+		return true
+	case vk >= 0xBA && vk <= 0xC0, vk >= 0xDB && vk <= 0xDE: // ;=,-./` [\]'
+		return true
+	}
+	return false
+}
+
+// currentModifiers 读取当前按住的修饰键
+func currentModifiers() Modifier {
+	var mods Modifier
+	if isKeyPressed(VK_CONTROL) {
+		mods |= ModCtrl
+	}
+	if isKeyPressed(VK_MENU) {
+		mods |= ModAlt
+	}
+	if isKeyPressed(VK_SHIFT) {
+		mods |= ModShift
+	}
+	if isKeyPressed(VK_LWIN) || isKeyPressed(VK_RWIN) {
+		mods |= ModSuper
+	}
+	return mods
+}
+
+// focusIsPassword 判断前台窗口的输入焦点是否为密码框
+// 只能识别标准 Edit 控件的 ES_PASSWORD 样式，浏览器等自绘控件无法识别
+func focusIsPassword() bool {
+	info := GUITHREADINFO{}
+	info.CbSize = uint32(unsafe.Sizeof(info))
+	if ret, _, _ := procGetGUIThreadInfo.Call(0, uintptr(unsafe.Pointer(&info))); ret == 0 || info.HwndFocus == 0 {
+		return false
+	}
+
+	var class [32]uint16
+	n, _ := win.GetClassName(info.HwndFocus, &class[0], len(class))
+	if n == 0 || !strings.EqualFold(syscall.UTF16ToString(class[:n]), "Edit") {
+		return false
+	}
+	return win.GetWindowLong(info.HwndFocus, win.GWL_STYLE)&win.ES_PASSWORD != 0
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"image"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 按键气泡的显示位置
+const (
+	KeystrokeAtCursor    = "cursor"
+	KeystrokeTopLeft     = "top_left"
+	KeystrokeTopRight    = "top_right"
+	KeystrokeBottomLeft  = "bottom_left"
+	KeystrokeBottomRight = "bottom_right"
+)
+
+const (
+	// 气泡离光标或屏幕边缘的距离，以及气泡之间的间距 (像素)
+	keystrokeMargin = 24
+	keystrokeGap    = 6
+	// 连续输入的字符合并到同一个气泡，最多保留的字符数
+	keystrokeMaxTyped = 24
+	// 同一气泡在该比例的持续时间内继续输入时才合并
+	keystrokeMergeLife = 0.5
+	// 隐私模式下代替密码字符显示的符号
+	keystrokeMask = "•"
+)
+
+// KeystrokeConfig 按键显示设置
+type KeystrokeConfig struct {
+	Enabled     bool     `json:"enabled"`
+	Position    string   `json:"position"`     // "cursor", "top_left", "top_right", "bottom_left", "bottom_right"
+	FontPath    string   `json:"font_path"`    // TTF/OTF/TTC 字体文件，为空时使用内置字体
+	FontSize    float64  `json:"font_size"`    // 字号 (像素)
+	Duration    float64  `json:"duration"`     // 气泡持续时间 (秒)
+	MaxBubbles  int      `json:"max_bubbles"`  // 同时显示的气泡数量上限
+	ShowTyping  bool     `json:"show_typing"`  // 是否显示不带修饰键的普通输入，关闭时只显示组合键
+	Privacy     bool     `json:"privacy"`      // 焦点在密码框时用圆点代替输入的字符
+	TextColor   [4]uint8 `json:"text_color"`   // RGBA
+	BubbleColor [4]uint8 `json:"bubble_color"` // RGBA
+}
+
+func defaultKeystrokeConfig() KeystrokeConfig {
+	return KeystrokeConfig{
+		Enabled:     false,
+		Position:    KeystrokeAtCursor,
+		FontSize:    18,
+		Duration:    1.5,
+		MaxBubbles:  4,
+		ShowTyping:  true,
+		Privacy:     true,
+		TextColor:   [4]uint8{255, 255, 255, 255},
+		BubbleColor: [4]uint8{30, 30, 30, 200},
+	}
+}
+
+// keyBubble 一个按键气泡
+type keyBubble struct {
+	label  string
+	count  int  // 同一组合键连续按下的次数
+	typing bool // 普通输入，后续字符会合并进来
+	life   float64
+
+	// 预渲染的气泡，label 改变时置空重建
+	rendered *textLabel
+}
+
+// invalidate 丢弃预渲染的气泡，下次绘制时重建
+func (b *keyBubble) invalidate() {
+	if b.rendered != nil {
+		b.rendered.Release()
+		b.rendered = nil
+	}
+}
+
+// KeystrokeOverlay 在光标附近或屏幕角落显示按下的按键
+type KeystrokeOverlay struct {
+	config  *Config
+	bubbles []keyBubble
+
+	// 光标位置
+	x, y int
+
+	// 上一次 Update 的时间
+	lastUpdate time.Time
+
+	font labelFont
+}
+
+func NewKeystrokeOverlay(cfg *Config) *KeystrokeOverlay {
+	return &KeystrokeOverlay{config: cfg}
+}
+
+// Add 记录一次按键
+func (k *KeystrokeOverlay) Add(ev KeyEvent) {
+	cfg := &k.config.Keystrokes
+	if !cfg.Enabled || ev.Key == "" {
+		return
+	}
+
+	// 只按了 Shift 的可打印字符视为普通输入
+	typed := ev.Mods&^ModShift == 0 && ev.Printable
+	if typed && !cfg.ShowTyping {
+		return
+	}
+
+	if typed {
+		text := ev.Key
+		if ev.Secret && cfg.Privacy {
+			text = keystrokeMask
+		} else if ev.Mods&ModShift == 0 {
+			text = strings.ToLower(text)
+		}
+
+		if n := len(k.bubbles); n > 0 && k.bubbles[n-1].typing && k.bubbles[n-1].life > keystrokeMergeLife {
+			b := &k.bubbles[n-1]
+			b.label = lastRunes(b.label+text, keystrokeMaxTyped)
+			b.life = 1
+			b.invalidate()
+			return
+		}
+		k.push(keyBubble{label: text, count: 1, typing: true, life: 1})
+		return
+	}
+
+	label := formatKeyEvent(ev)
+	if ev.Secret && cfg.Privacy && ev.Mods&^ModShift == 0 {
+		label = keystrokeMask
+	}
+	// 重复按下同一组合键时显示次数
+	if n := len(k.bubbles); n > 0 && !k.bubbles[n-1].typing && k.bubbles[n-1].label == label {
+		b := &k.bubbles[n-1]
+		b.count++
+		b.life = 1
+		b.invalidate()
+		return
+	}
+	k.push(keyBubble{label: label, count: 1, life: 1})
+}
+
+// push 添加气泡，超过 MaxBubbles 时丢弃最早的气泡并释放它们的预渲染图片
+func (k *KeystrokeOverlay) push(b keyBubble) {
+	k.bubbles = append(k.bubbles, b)
+	if limit := max(k.config.Keystrokes.MaxBubbles, 1); len(k.bubbles) > limit {
+		drop := len(k.bubbles) - limit
+		for i := range drop {
+			k.bubbles[i].invalidate()
+		}
+		n := copy(k.bubbles, k.bubbles[drop:])
+		clear(k.bubbles[n:])
+		k.bubbles = k.bubbles[:n]
+	}
+}
+
+// Update 推进淡出动画并记录光标位置，返回是否还有需要绘制的气泡
+func (k *KeystrokeOverlay) Update(now time.Time, x, y int) bool {
+	dt := elapsedSeconds(k.lastUpdate, now)
+	k.lastUpdate = now
+	k.x, k.y = x, y
+
+	decay := 1.0
+	if d := k.config.Keystrokes.Duration; d > 0 {
+		decay = dt / d
+	}
+	active := 0
+	for i := range k.bubbles {
+		k.bubbles[i].life -= decay
+		if k.bubbles[i].life > 0 {
+			k.bubbles[active] = k.bubbles[i]
+			active++
+		} else {
+			// 消失的气泡释放预渲染的图片
+			k.bubbles[i].invalidate()
+		}
+	}
+	clear(k.bubbles[active:])
+	k.bubbles = k.bubbles[:active]
+	return active > 0
+}
+
+// Draw 把气泡绘制到 screen
+func (k *KeystrokeOverlay) Draw(screen *ebiten.Image) {
+	k.layout(screen.Bounds(), func(b *keyBubble, x, y int) {
+		b.rendered.Draw(screen, x, y, bubbleAlpha(b.life))
+	})
+}
+
+// DrawRGBA 使用软件合成把气泡绘制到 dst，不需要窗口和 GPU
+func (k *KeystrokeOverlay) DrawRGBA(dst *image.RGBA) {
+	k.layout(dst.Bounds(), func(b *keyBubble, x, y int) {
+		b.rendered.DrawRGBA(dst, x, y, bubbleAlpha(b.life))
+	})
+}
+
+// bubbleAlpha 气泡在生命的最后一部分才开始淡出
+func bubbleAlpha(life float64) float64 {
+	return math.Min(1, life*3)
+}
+
+// layout 计算每个气泡的位置并调用 draw，最新的气泡离锚点最近
+func (k *KeystrokeOverlay) layout(bounds image.Rectangle, drawBubble func(b *keyBubble, x, y int)) {
+	if len(k.bubbles) == 0 {
+		return
+	}
+	k.ensureFace()
+
+	cfg := &k.config.Keystrokes
+	alignRight := cfg.Position == KeystrokeTopRight || cfg.Position == KeystrokeBottomRight
+	stackUp := cfg.Position == KeystrokeBottomLeft || cfg.Position == KeystrokeBottomRight
+
+	var ax, ay int
+	switch cfg.Position {
+	case KeystrokeTopLeft:
+		ax, ay = bounds.Min.X+keystrokeMargin, bounds.Min.Y+keystrokeMargin
+	case KeystrokeTopRight:
+		ax, ay = bounds.Max.X-keystrokeMargin, bounds.Min.Y+keystrokeMargin
+	case KeystrokeBottomLeft:
+		ax, ay = bounds.Min.X+keystrokeMargin, bounds.Max.Y-keystrokeMargin
+	case KeystrokeBottomRight:
+		ax, ay = bounds.Max.X-keystrokeMargin, bounds.Max.Y-keystrokeMargin
+	default:
+		// 光标右下方，避开光标本身
+		ax, ay = k.x+keystrokeMargin, k.y+keystrokeMargin
+	}
+
+	y := ay
+	for i := len(k.bubbles) - 1; i >= 0; i-- {
+		b := &k.bubbles[i]
+		if b.rendered == nil {
+			b.rendered = k.renderBubble(b)
+		}
+		size := b.rendered.Size()
+
+		x := ax
+		if alignRight {
+			x -= size.X
+		}
+		if stackUp {
+			y -= size.Y
+			drawBubble(b, x, y)
+			y -= keystrokeGap
+		} else {
+			drawBubble(b, x, y)
+			y += size.Y + keystrokeGap
+		}
+	}
+}
+
+// renderBubble 渲染气泡的文字标签
+func (k *KeystrokeOverlay) renderBubble(b *keyBubble) *textLabel {
+	cfg := &k.config.Keystrokes
+	text := b.label
+	if b.count > 1 {
+		text += " ×" + strconv.Itoa(b.count)
+	}
+	return newTextLabel(&k.font, text, cfg.TextColor, cfg.BubbleColor)
+}
+
+// ensureFace 按当前设置加载字体，设置改变时重建所有气泡
+func (k *KeystrokeOverlay) ensureFace() {
+	cfg := &k.config.Keystrokes
+	size := cfg.FontSize
+	if size <= 0 {
+		size = defaultKeystrokeConfig().FontSize
+	}
+	if k.font.ensure(cfg.FontPath, size) {
+		for i := range k.bubbles {
+			k.bubbles[i].invalidate()
+		}
+	}
+}
+
+// formatKeyEvent 把按键格式化为 "Ctrl+Shift+S" 的形式
+func formatKeyEvent(ev KeyEvent) string {
+	if ev.Mods == 0 {
+		return ev.Key
+	}
+	return ev.Mods.String() + "+" + ev.Key
+}
+
+// lastRunes 返回 s 的最后 n 个字符
+func lastRunes(s string, n int) string {
+	for utf8.RuneCountInString(s) > n {
+		_, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+	}
+	return s
+}
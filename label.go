@@ -0,0 +1,149 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"log"
+	"math"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// labelFont 按需加载的字体，字体文件或字号改变时重新加载
+type labelFont struct {
+	face font.Face
+	path string
+	size float64
+}
+
+// ensure 保证字体与设置一致，返回 true 表示重新加载过 (之前渲染的标签需要重建)
+func (f *labelFont) ensure(path string, size float64) bool {
+	if f.face != nil && f.path == path && f.size == size {
+		return false
+	}
+	if f.face != nil {
+		f.face.Close()
+	}
+	f.face = loadFace(path, size)
+	f.path, f.size = path, size
+	return true
+}
+
+// textLabel 预渲染的圆角背景文字标签
+// 先渲染到 image.RGBA，GPU 路径再按需上传，软件路径直接合成
+type textLabel struct {
+	img  *image.RGBA
+	eimg *ebiten.Image
+}
+
+// newTextLabel 用 f 渲染 text，内边距与字号成比例
+func newTextLabel(f *labelFont, text string, textColor, bgColor [4]uint8) *textLabel {
+	metrics := f.face.Metrics()
+	ascent := metrics.Ascent.Ceil()
+	textHeight := ascent + metrics.Descent.Ceil()
+	textWidth := font.MeasureString(f.face, text).Ceil()
+	pad := int(math.Ceil(f.size * 0.5))
+
+	w, h := textWidth+pad*2, textHeight+pad
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	fillRoundedRect(img, float64(h)/4, bgColor)
+
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(color.NRGBA{textColor[0], textColor[1], textColor[2], textColor[3]}),
+		Face: f.face,
+		Dot:  fixed.P(pad, pad/2+ascent),
+	}
+	d.DrawString(text)
+	return &textLabel{img: img}
+}
+
+// Size 返回标签的像素尺寸
+func (l *textLabel) Size() image.Point {
+	return l.img.Bounds().Size()
+}
+
+// Draw 把标签以 alpha 透明度绘制到 screen 的 (x, y)
+func (l *textLabel) Draw(screen *ebiten.Image, x, y int, alpha float64) {
+	if l.eimg == nil {
+		l.eimg = ebiten.NewImageFromImage(l.img)
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(x), float64(y))
+	op.ColorScale.ScaleAlpha(float32(alpha))
+	screen.DrawImage(l.eimg, op)
+}
+
+// DrawRGBA 把标签以 alpha 透明度合成到 dst 的 (x, y)
+func (l *textLabel) DrawRGBA(dst *image.RGBA, x, y int, alpha float64) {
+	mask := image.NewUniform(color.Alpha{A: uint8(math.Max(0, math.Min(1, alpha)) * 255)})
+	r := l.img.Bounds().Add(image.Pt(x, y))
+	draw.DrawMask(dst, r, l.img, image.Point{}, mask, image.Point{}, draw.Over)
+}
+
+// Release 释放 GPU 纹理
+func (l *textLabel) Release() {
+	if l.eimg != nil {
+		l.eimg.Deallocate()
+		l.eimg = nil
+	}
+}
+
+// loadFace 加载字体文件，失败时回退到内置的 Go 字体
+// 字体集合 (.ttc，如微软雅黑) 使用其中的第一个字体
+func loadFace(path string, size float64) font.Face {
+	opts := &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull}
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var f *opentype.Font
+			var coll *opentype.Collection
+			if coll, err = opentype.ParseCollection(data); err == nil {
+				f, err = coll.Font(0)
+			}
+			if err == nil {
+				var face font.Face
+				if face, err = opentype.NewFace(f, opts); err == nil {
+					return face
+				}
+			}
+		}
+		log.Printf("Failed to load font %s, using the built-in font: %v", path, err)
+	}
+
+	f, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		log.Fatal(err)
+	}
+	face, err := opentype.NewFace(f, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return face
+}
+
+// fillRoundedRect 用圆角矩形填满 img，边缘做 1 像素的抗锯齿
+func fillRoundedRect(img *image.RGBA, radius float64, c [4]uint8) {
+	b := img.Bounds()
+	w, h := float64(b.Dx()), float64(b.Dy())
+	for py := b.Min.Y; py < b.Max.Y; py++ {
+		for px := b.Min.X; px < b.Max.X; px++ {
+			// 到最近圆角圆心的距离，直边部分为 0
+			x, y := float64(px)+0.5, float64(py)+0.5
+			dx := math.Max(0, math.Max(radius-x, x-(w-radius)))
+			dy := math.Max(0, math.Max(radius-y, y-(h-radius)))
+			coverage := math.Max(0, math.Min(1, radius-math.Hypot(dx, dy)+0.5))
+			if coverage <= 0 {
+				continue
+			}
+			img.Set(px, py, color.NRGBA{c[0], c[1], c[2], uint8(float64(c[3]) * coverage)})
+		}
+	}
+}
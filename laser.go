@@ -0,0 +1,105 @@
+package main
+
+import (
+	"time"
+)
+
+const (
+	// 光点外圈和白色内芯相对 DotRadius 的比例
+	laserDotOuter = 2.2
+	laserDotCore  = 0.45
+	// 光点的边数
+	laserDotSegments = 24
+)
+
+// laserGlow 激光笔模式使用的辉光，与 Config.Glow 无关
+var laserGlow = GlowConfig{Enabled: true, Radius: 14, Intensity: 2}
+
+// LaserConfig 激光笔模式设置
+// 开启后用短而亮、带辉光的轨迹和头部光点代替普通轨迹，关闭后恢复原来的轨迹设置
+type LaserConfig struct {
+	Enabled    bool     `json:"enabled"`     // 是否处于激光笔模式，可用热键 (Hotkeys.Laser) 或托盘菜单切换
+	Color      [4]uint8 `json:"color"`       // RGBA
+	Width      float64  `json:"width"`       // 轨迹宽度 (像素)
+	Length     int      `json:"length"`      // 轨迹最多使用的采样点数
+	Lifetime   float64  `json:"lifetime"`    // 轨迹采样点存活时间 (秒)
+	DotRadius  float64  `json:"dot_radius"`  // 头部光点半径 (像素)
+	HideCursor bool     `json:"hide_cursor"` // 激光笔模式下隐藏系统光标
+	AutoOff    float64  `json:"auto_off"`    // 光标静止多少秒后自动回到普通轨迹，0 表示不自动退出
+}
+
+func defaultLaserConfig() LaserConfig {
+	return LaserConfig{
+		Enabled:   false,
+		Color:     [4]uint8{255, 30, 30, 255},
+		Width:     5,
+		Length:    12,
+		Lifetime:  0.18,
+		DotRadius: 7,
+		AutoOff:   10,
+	}
+}
+
+// laserState 激光笔模式的状态
+type laserState struct {
+	wasOn    bool
+	lastMove time.Time    // 光标最后一次移动的时间，用于自动退出
+	idle     bool         // 光标已静止超过 AutoOff 秒
+	layers   []TrailLayer // 激光轨迹的图层，每帧复用
+}
+
+// updateLaser 记录光标移动，判断光标是否静止超过 AutoOff 秒
+// 只记录状态而不修改配置，由 Game 执行关闭激光笔模式的操作
+func (tm *TraceManager) updateLaser(moved bool, now time.Time) {
+	cfg := &tm.config.Laser
+	l := &tm.laser
+	if moved || !l.wasOn {
+		l.lastMove = now
+	}
+	l.wasOn = cfg.Enabled
+	l.idle = cfg.Enabled && cfg.AutoOff > 0 && elapsedSeconds(l.lastMove, now) > cfg.AutoOff
+}
+
+// LaserIdle 返回激光笔模式下光标是否已静止超过 AutoOff 秒，应自动回到普通轨迹
+func (tm *TraceManager) LaserIdle() bool {
+	return tm.laser.idle
+}
+
+// activeLayers 返回当前使用的轨迹图层：激光笔模式下为激光的预设图层，否则为 Config.Layers
+func (tm *TraceManager) activeLayers() []TrailLayer {
+	cfg := &tm.config.Laser
+	if !cfg.Enabled {
+		return tm.config.Layers
+	}
+
+	// 宽而淡的光晕、实心的光束和白色内芯
+	scale := cfg.Width / max(tm.config.TailWidth, 1)
+	white := [4]uint8{255, 255, 255, 255}
+	tm.laser.layers = append(tm.laser.layers[:0],
+		TrailLayer{Enabled: true, Length: cfg.Length, Lifetime: cfg.Lifetime, WidthScale: scale * 2.2, Color: cfg.Color, Opacity: 0.35},
+		TrailLayer{Enabled: true, Length: cfg.Length, Lifetime: cfg.Lifetime, WidthScale: scale, Color: cfg.Color},
+		TrailLayer{Enabled: true, Length: cfg.Length, Lifetime: cfg.Lifetime, WidthScale: scale * 0.4, Color: white, Opacity: 0.9},
+	)
+	return tm.laser.layers
+}
+
+// glowConfig 返回当前使用的辉光设置
+func (tm *TraceManager) glowConfig() *GlowConfig {
+	if tm.config.Laser.Enabled {
+		return &laserGlow
+	}
+	return &tm.config.Glow
+}
+
+// buildLaserGeometry 在光标处生成激光笔的光点：淡色外圈、实心光点和白色内芯
+// 光标静止时光点仍然保留
+func (tm *TraceManager) buildLaserGeometry() {
+	cfg := &tm.config.Laser
+	if !cfg.Enabled || cfg.DotRadius <= 0 {
+		return
+	}
+	x, y, r := tm.lastX, tm.lastY, cfg.DotRadius
+	tm.addDisc(x, y, r*laserDotOuter, laserDotSegments, tm.styleColor(cfg.Color, 0.25))
+	tm.addDisc(x, y, r, laserDotSegments, tm.styleColor(cfg.Color, 1))
+	tm.addDisc(x, y, r*laserDotCore, laserDotSegments, vertexColor{1, 1, 1, 1})
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// 轨迹图层预设
+const (
+	LayerPresetNone     = ""          // 单条轨迹
+	LayerPresetGlowCore = "glow_core" // 宽而淡的外层加细而亮的内芯
+	LayerPresetGhost    = "ghost"     // 正常轨迹加一条延迟的淡影
+)
+
+const (
+	// 配置了图层时保留的采样点数量上限
+	maxHistoryPoints = 4096
+)
+
+// TrailLayer 轨迹图层
+// 为 0 (或空) 的字段沿用顶层的轨迹设置
+type TrailLayer struct {
+	Enabled    bool           `json:"enabled"`
+	Length     int            `json:"length"`      // 最多使用的采样点数
+	WidthScale float64        `json:"width_scale"` // 宽度相对 TailWidth 的倍数
+	Lifetime   float64        `json:"lifetime"`    // 采样点存活时间 (秒)
+	Delay      float64        `json:"delay"`       // 延迟 (秒)，图层只显示至少这么久之前的采样点
+	Color      [4]uint8       `json:"color"`       // RGBA，alpha 为 0 时沿用轨迹的颜色 (包括彩虹和渐变)
+	Gradient   []GradientStop `json:"gradient"`    // 图层自己的渐变，优先于 Color
+	Opacity    float64        `json:"opacity"`     // 透明度系数
+	OffsetX    float64        `json:"offset_x"`    // 整体位移 (像素)
+	OffsetY    float64        `json:"offset_y"`
+}
+
+// resolve 返回用顶层设置补全后的图层
+func (l TrailLayer) resolve(cfg *Config) TrailLayer {
+	if l.Length <= 0 {
+		l.Length = cfg.TailLength
+	}
+	if l.WidthScale <= 0 {
+		l.WidthScale = 1
+	}
+	if l.Lifetime <= 0 {
+		l.Lifetime = cfg.TailLifetime
+	}
+	if l.Opacity <= 0 {
+		l.Opacity = 1
+	}
+	return l
+}
+
+// trailLayerPreset 返回预设对应的图层列表
+func trailLayerPreset(name string) []TrailLayer {
+	switch name {
+	case LayerPresetGlowCore:
+		return []TrailLayer{
+			{Enabled: true, WidthScale: 2.5, Opacity: 0.3},
+			{Enabled: true, WidthScale: 0.4, Color: [4]uint8{255, 255, 255, 255}},
+		}
+	case LayerPresetGhost:
+		return []TrailLayer{
+			{Enabled: true},
+			{Enabled: true, Delay: 0.25, Opacity: 0.35},
+		}
+	}
+	return nil
+}
+
+// trailLayerPresets 所有图层预设，按切换顺序排列
+var trailLayerPresets = []string{LayerPresetNone, LayerPresetGlowCore, LayerPresetGhost}
+
+// trailLayerPresetName 返回与 layers 相同的预设名称，都不相同时返回 false
+func trailLayerPresetName(layers []TrailLayer) (string, bool) {
+	if len(layers) == 0 {
+		return LayerPresetNone, true
+	}
+	for _, name := range trailLayerPresets[1:] {
+		if reflect.DeepEqual(layers, trailLayerPreset(name)) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// nextTrailLayerPreset 返回 layers 之后的下一组图层和保存的自定义图层
+// 依次切换各个预设，custom 不为空时最后一个预设之后恢复 custom；
+// 当前为自定义图层时保存到返回的 stash 中，再切换到第一个预设
+func nextTrailLayerPreset(layers, custom []TrailLayer) (next, stash []TrailLayer) {
+	name, ok := trailLayerPresetName(layers)
+	if !ok {
+		return trailLayerPreset(trailLayerPresets[0]), layers
+	}
+	i := slices.Index(trailLayerPresets, name)
+	if i == len(trailLayerPresets)-1 && custom != nil {
+		return custom, nil
+	}
+	return trailLayerPreset(trailLayerPresets[(i+1)%len(trailLayerPresets)]), custom
+}
+
+// historyWindow 图层需要保留的采样点时长 (秒)
+func (tm *TraceManager) historyWindow() float64 {
+	window := 0.0
+	for _, l := range tm.activeLayers() {
+		l = l.resolve(tm.config)
+		window = math.Max(window, l.Delay+l.Lifetime)
+	}
+	return window
+}
+
+// pruneHistory 配置了图层时按时间而不是生命值移除过期的采样点
+func (tm *TraceManager) pruneHistory(now time.Time) {
+	window := tm.historyWindow()
+	start := 0
+	for start < len(tm.points) && elapsedSeconds(tm.points[start].Time, now) > window {
+		start++
+	}
+	start = max(start, len(tm.points)-maxHistoryPoints)
+	tm.points = append(tm.points[:0], tm.points[start:]...)
+}
+
+// layerPoints 按图层的延迟、存活时间、长度和位移从采样点生成图层的轨迹点到 tm.layerPts
+// 结果从尾端排列到头部，Life 按图层的存活时间重新计算
+func (tm *TraceManager) layerPoints(l *TrailLayer, now time.Time) []TracePoint {
+	tm.layerPts = tm.layerPts[:0]
+	for i := len(tm.points) - 1; i >= 0 && len(tm.layerPts) < l.Length; i-- {
+		p := tm.points[i]
+		age := elapsedSeconds(p.Time, now) - l.Delay
+		if age < 0 {
+			continue
+		}
+		p.Life = 1 - age/l.Lifetime
+		if p.Life <= 0 {
+			break
+		}
+		p.X += l.OffsetX
+		p.Y += l.OffsetY
+		tm.layerPts = append(tm.layerPts, p)
+	}
+	// 反转为从尾端到头部
+	for i, j := 0, len(tm.layerPts)-1; i < j; i, j = i+1, j-1 {
+		tm.layerPts[i], tm.layerPts[j] = tm.layerPts[j], tm.layerPts[i]
+	}
+	return tm.layerPts
+}
@@ -3,207 +3,29 @@ package main
 import (
 	"log"
 	"runtime"
-	"syscall"
-	"time"
-	"unsafe"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/lxn/win"
 )
 
-const (
-	GWL_EXSTYLE       = -20
-	WS_EX_TOOLWINDOW  = 0x00000080
-	WS_EX_APPWINDOW   = 0x00040000
-	WS_EX_LAYERED     = 0x00080000
-	WS_EX_TRANSPARENT = 0x00000020
-	SWP_NOSIZE        = 0x0001
-	SWP_NOMOVE        = 0x0002
-	SWP_NOZORDER      = 0x0004
-	SWP_FRAMECHANGED  = 0x0020
-	SWP_NOACTIVATE    = 0x0010
-	LWA_COLORKEY      = 0x00000001
-	LWA_ALPHA         = 0x00000002
-	VK_LBUTTON        = 0x01
-)
-
-var (
-	user32dll                        = syscall.NewLazyDLL("user32.dll")
-	procSetLayeredWindowAttributes   = user32dll.NewProc("SetLayeredWindowAttributes")
-	procGetAsyncKeyState             = user32dll.NewProc("GetAsyncKeyState")
-	dwmapi                           = syscall.NewLazyDLL("dwmapi.dll")
-	procDwmExtendFrameIntoClientArea = dwmapi.NewProc("DwmExtendFrameIntoClientArea")
-)
-
-// isMouseLeftPressed 使用 GetAsyncKeyState 检测鼠标左键状态
-// 这可以绕过 WS_EX_TRANSPARENT 导致的 Ebiten 无法接收鼠标事件的问题
-func isMouseLeftPressed() bool {
-	ret, _, _ := procGetAsyncKeyState.Call(uintptr(VK_LBUTTON))
-	// 如果最高位被设置 (0x8000)，则表示键被按下
-	return (ret & 0x8000) != 0
-}
-
-type MARGINS struct {
-	CxLeftWidth    int32
-	CxRightWidth   int32
-	CyTopHeight    int32
-	CyBottomHeight int32
-}
-
-type Game struct {
-	traceManager *TraceManager
-	config       *Config
-	quitChan     chan struct{}
-
-	screenWidth  int
-	screenHeight int
-
-	// 窗口句柄
-	hwnd win.HWND
-
-	// 性能优化：空闲检测
-	idleCounter int
-
-	// 鼠标状态
-	prevLeftMouseButtonPressed bool
-
-	// 缓存窗口位置，避免频繁调用 GetWindowRect
-	cachedWindowRect win.RECT
-	rectUpdateTimer  int
-}
-
-func (g *Game) Update() error {
-	// 检查退出信号
-	select {
-	case <-g.quitChan:
-		return ebiten.Termination
-	default:
-	}
-
-	// 极度空闲优化：如果 idleCounter 很高，跳过一些帧的逻辑更新？
-	// Ebiten 还是会调用 Draw，但我们可以减少 Update 的频率
-	if g.idleCounter > 300 { // 5秒无操作
-		ebiten.SetTPS(5) // 极低刷新率
-	}
-
-	// 获取鼠标位置
-	var pt win.POINT
-	win.GetCursorPos(&pt)
-
-	// 计算相对于窗口的坐标
-	// 如果找到了窗口句柄，就用真实的窗口位置计算
-	// 否则回退到虚拟屏幕计算（虽然这可能是错的）
-	mx, my := 0, 0
-
-	if g.hwnd != 0 {
-		// 缓存窗口位置，每 60 帧更新一次 (约 1 秒)
-		g.rectUpdateTimer++
-		if g.rectUpdateTimer > 60 || g.cachedWindowRect.Right == 0 {
-			win.GetWindowRect(g.hwnd, &g.cachedWindowRect)
-			g.rectUpdateTimer = 0
-		}
-		rect := g.cachedWindowRect
-
-		// 使用比例映射来解决 DPI 缩放导致的不一致问题
-		// 窗口的物理像素大小
-		windowWidth := int(rect.Right - rect.Left)
-		windowHeight := int(rect.Bottom - rect.Top)
-
-		// 避免除以 0
-		if windowWidth > 0 && windowHeight > 0 {
-			// 计算鼠标相对于窗口左上角的偏移量
-			offsetX := int(pt.X) - int(rect.Left)
-			offsetY := int(pt.Y) - int(rect.Top)
-
-			// 计算归一化比例 (0.0 - 1.0)
-			rx := float64(offsetX) / float64(windowWidth)
-			ry := float64(offsetY) / float64(windowHeight)
-
-			// 映射到 Ebiten 的 Layout 坐标系
-			mx = int(rx * float64(g.screenWidth))
-			my = int(ry * float64(g.screenHeight))
-		} else {
-			// 如果窗口大小异常，回退到简单差值
-			mx = int(pt.X) - int(rect.Left)
-			my = int(pt.Y) - int(rect.Top)
-		}
-	} else {
-		// 尝试查找窗口句柄 (如果 main 中的协程还没找到)
-		// 注意：频繁 FindWindow 可能有开销，但这里只有在找不到时才调用
-		titlePtr := syscall.StringToUTF16Ptr("MouseFlowOverlay")
-		hwnd := win.FindWindow(nil, titlePtr)
-		if hwnd != 0 {
-			g.hwnd = hwnd
-			// 递归调用一次或直接使用上面的逻辑，为了简单，这里重复逻辑但简化
-			var rect win.RECT
-			win.GetWindowRect(g.hwnd, &rect)
-			windowWidth := int(rect.Right - rect.Left)
-			windowHeight := int(rect.Bottom - rect.Top)
-			if windowWidth > 0 && windowHeight > 0 {
-				offsetX := int(pt.X) - int(rect.Left)
-				offsetY := int(pt.Y) - int(rect.Top)
-				rx := float64(offsetX) / float64(windowWidth)
-				ry := float64(offsetY) / float64(windowHeight)
-				mx = int(rx * float64(g.screenWidth))
-				my = int(ry * float64(g.screenHeight))
-			} else {
-				mx = int(pt.X) - int(rect.Left)
-				my = int(pt.Y) - int(rect.Top)
-			}
-		} else {
-			// 实在找不到，暂时使用虚拟屏幕原点
-			x := win.GetSystemMetrics(win.SM_XVIRTUALSCREEN)
-			y := win.GetSystemMetrics(win.SM_YVIRTUALSCREEN)
-			mx = int(pt.X) - int(x)
-			my = int(pt.Y) - int(y)
-		}
-	}
-
-	// 检测鼠标点击 (波纹效果)
-	// 使用 GetAsyncKeyState 代替 ebiten.IsMouseButtonPressed
-	leftPressed := isMouseLeftPressed()
-	if leftPressed && !g.prevLeftMouseButtonPressed {
-		g.traceManager.AddRipple(mx, my)
-	}
-	g.prevLeftMouseButtonPressed = leftPressed
-
-	isActive := g.traceManager.Update(mx, my)
-
-	// 智能休眠逻辑
-	if isActive {
-		g.idleCounter = 0
-		ebiten.SetTPS(60) // 恢复高刷新率以保证流畅动画
-	} else {
-		g.idleCounter++
-		if g.idleCounter > 300 {
-			ebiten.SetTPS(5) // 极低刷新率
-		} else if g.idleCounter > 60 { // 约 1 秒无活动
-			ebiten.SetTPS(15) // 降低刷新率以节省 CPU/GPU
-		}
-	}
-
-	// 如果彩虹模式
-	if g.config.IsRainbow {
-		g.updateRainbow()
-	}
-
-	return nil
-}
-
-func (g *Game) updateRainbow() {
-	// 简单的颜色循环
-	g.config.TailColor[0] = uint8((int(g.config.TailColor[0]) + 1) % 255)
-	g.config.TailColor[1] = uint8((int(g.config.TailColor[1]) + 2) % 255)
-	g.config.TailColor[2] = uint8((int(g.config.TailColor[2]) + 3) % 255)
-}
-
-func (g *Game) Draw(screen *ebiten.Image) {
-	// 绘制轨迹
-	g.traceManager.Draw(screen)
-}
-
-func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return g.screenWidth, g.screenHeight
+// overlayTitle 覆盖窗口标题，各平台后端通过它查找 Ebiten 创建的窗口
+const overlayTitle = "MouseFlowOverlay"
+
+// OverlayBackend 平台相关的覆盖窗口后端
+// 负责确定覆盖区域、提供输入源，以及把 Ebiten 窗口调整为
+// 鼠标穿透、始终置顶、不出现在任务栏的透明覆盖层
+type OverlayBackend interface {
+	// Bounds 返回覆盖窗口应占据的区域 (屏幕坐标)
+	Bounds() (x, y, w, h int)
+	// Input 返回该平台的输入源
+	Input() InputSource
+	// Start 在 ebiten.RunGame 之前调用，启动窗口样式和置顶维护
+	Start(quitChan chan struct{})
+	// SetCursorHidden 隐藏或恢复系统光标，退出前必须恢复
+	SetCursorHidden(hidden bool)
+	// SetKeyboardCapture 开始或停止读取键盘，关闭时输入源不再报告按键事件
+	SetKeyboardCapture(on bool)
+	// Close 在 ebiten.RunGame 返回后调用，停止后台维护并释放平台资源
+	Close()
 }
 
 func main() {
@@ -217,42 +39,62 @@ func main() {
 	// 设置语言
 	SetLanguage(cfg.Language)
 
-	// 获取虚拟屏幕位置和尺寸
-	vx := int(win.GetSystemMetrics(win.SM_XVIRTUALSCREEN))
-	vy := int(win.GetSystemMetrics(win.SM_YVIRTUALSCREEN))
-	vw := int(win.GetSystemMetrics(win.SM_CXVIRTUALSCREEN))
-	vh := int(win.GetSystemMetrics(win.SM_CYVIRTUALSCREEN))
+	overlay, err := newOverlayBackend()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// 获取覆盖区域位置和尺寸
+	vx, vy, vw, vh := overlay.Bounds()
 
 	// 通信通道
 	quitChan := make(chan struct{})
-	openConfigChan := make(chan struct{})
+	openConfigChan := make(chan *Config)
+	configChan := make(chan ConfigEdit, 1)
+	actionChan := make(chan OverlayAction, 8)
 
-	// 启动托盘
-	go RunTray(quitChan, openConfigChan)
+	// 启动托盘，托盘线程只使用自己的配置副本，菜单状态由游戏线程发布
+	publishTrayState(cfg)
+	go RunTray(cfg.Clone(), quitChan, actionChan)
 
 	// 监听配置请求
+	// 设置窗口只修改游戏线程交给它的副本，每次修改后把改变的字段交回游戏线程应用
 	go func() {
 		// GUI 线程需要锁定
 		runtime.LockOSThread()
-		for range openConfigChan {
+		for edit := range openConfigChan {
 			log.Println("Opening config window...")
-			ShowConfigWindow(cfg, func() {
-				// 配置更新时的回调
+			// 上一次交给游戏线程时的副本
+			before := edit.Clone()
+			ShowConfigWindow(edit, func() {
+				e := ConfigEdit{Before: before, After: edit.Clone()}
+				before = edit.Clone()
+				// 游戏线程尚未应用上一次修改时合并为一次，只有这里发送，不会阻塞
+				select {
+				case pending := <-configChan:
+					e.Before = pending.Before
+				default:
+				}
+				configChan <- e
 			})
+			// 没有保存就关闭窗口时，修改同样生效，按新的设置重新注册全局热键
+			ReloadHotkeys(edit)
 		}
 	}()
 
-	// Hack: 增加高度以避免 Windows 将其识别为独占全屏应用，从而导致 DWM 透明失效
-	// 特别是在单显示器环境下
-	vh += 1
-
 	// 初始化游戏
 	game := &Game{
-		traceManager: NewTraceManager(cfg),
-		config:       cfg,
-		quitChan:     quitChan,
-		screenWidth:  vw,
-		screenHeight: vh,
+		traceManager:   NewTraceManager(cfg),
+		keystrokes:     NewKeystrokeOverlay(cfg),
+		config:         cfg,
+		input:          overlay.Input(),
+		overlay:        overlay,
+		quitChan:       quitChan,
+		actionChan:     actionChan,
+		openConfigChan: openConfigChan,
+		configChan:     configChan,
+		screenWidth:    vw,
+		screenHeight:   vh,
 	}
 
 	// Ebiten 设置
@@ -267,93 +109,16 @@ func main() {
 	// 初始设置为鼠标穿透
 	ebiten.SetWindowMousePassthrough(true)
 
-	title := "MouseFlowOverlay"
-	ebiten.SetWindowTitle(title)
+	ebiten.SetWindowTitle(overlayTitle)
 
-	// 隐藏任务栏图标并强制全屏覆盖
-	go func() {
-		// 尝试多次，以防窗口创建延迟
-		ticker := time.NewTicker(100 * time.Millisecond)
-		defer ticker.Stop()
+	// 隐藏任务栏图标、强制全屏覆盖并维护置顶
+	overlay.Start(quitChan)
 
-		titlePtr := syscall.StringToUTF16Ptr(title)
-		applyCount := 0
-
-		for range ticker.C {
-			// FindWindow 可能找不到，如果标题还没设置好。
-			// Ebiten 的默认类名不确定，所以用 nil
-			hwnd := win.FindWindow(nil, titlePtr)
-			if hwnd != 0 {
-				// 获取当前扩展样式
-				exStyle := win.GetWindowLong(hwnd, GWL_EXSTYLE)
-
-				// 添加 LAYERED 以确保 Windows 复合透明正常工作
-				// 移除 WS_EX_LAYERED，因为 DWM 玻璃效果不需要它，且它可能与 DX 冲突
-				newExStyle := (exStyle & ^WS_EX_APPWINDOW) | WS_EX_TOOLWINDOW | WS_EX_TRANSPARENT
-
-				if newExStyle != exStyle {
-					win.SetWindowLong(hwnd, GWL_EXSTYLE, newExStyle)
-					log.Println("Window style updated to hide from taskbar with passthrough")
-				}
-
-				// 移除 SetLayeredWindowAttributes 调用，因为它会破坏 DWM 玻璃效果
-
-				// 使用 DWM 扩展玻璃到整个客户端区域，避免黑底
-				m := MARGINS{-1, -1, -1, -1}
-				_, _, _ = procDwmExtendFrameIntoClientArea.Call(
-					uintptr(hwnd), uintptr(unsafe.Pointer(&m)),
-				)
-
-				// 强制设置窗口位置和大小，覆盖整个虚拟屏幕
-				// 即使 Ebiten/GLFW 试图限制它，我们也强制覆盖
-				// 使用 HWND_TOPMOST (-1) 确保窗口在最上层
-				// 移除 SWP_NOZORDER 以允许改变 Z 序
-				win.SetWindowPos(hwnd, win.HWND_TOPMOST, int32(vx), int32(vy), int32(vw), int32(vh),
-					SWP_NOACTIVATE|SWP_FRAMECHANGED)
-
-				applyCount++
-				if applyCount > 5 {
-					// 设置 HWND 给 Game
-					game.hwnd = hwnd
-					// 再次检查确认
-					currentStyle := win.GetWindowLong(hwnd, GWL_EXSTYLE)
-					if currentStyle&WS_EX_TOOLWINDOW != 0 {
-						return
-					}
-				}
-			}
-		}
-	}()
-
-	// 解决 walk 库可能的初始化问题
-	// 需要确保 InitCommonControls 被调用，不过 walk 包通常会在 init 中做。
-	// 关键是 manifest 文件。
-
-	// 启动一个协程定期维护窗口置顶状态，防止被新打开的窗口遮挡
-	go func() {
-		// 等待初始化完成
-		time.Sleep(2 * time.Second)
-
-		ticker := time.NewTicker(100 * time.Millisecond) // 提高频率到 100ms
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-quitChan:
-				return
-			case <-ticker.C:
-				if game.hwnd != 0 {
-					// 仅维护 Z 序，不改变大小和位置
-					win.SetWindowPos(game.hwnd, win.HWND_TOPMOST, 0, 0, 0, 0,
-						SWP_NOMOVE|SWP_NOSIZE|SWP_NOACTIVATE)
-				}
-			}
-		}
-	}()
-
-	if err := ebiten.RunGame(game); err != nil {
-		if err != ebiten.Termination {
-			log.Fatal(err)
-		}
+	err = ebiten.RunGame(game)
+	// 激光笔模式可能隐藏了系统光标
+	overlay.SetCursorHidden(false)
+	overlay.Close()
+	if err != nil && err != ebiten.Termination {
+		log.Fatal(err)
 	}
 }
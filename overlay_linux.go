@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/shape"
+	"github.com/jezek/xgb/xfixes"
+	"github.com/jezek/xgb/xproto"
+)
+
+// x11Overlay 基于 X11 的覆盖窗口后端
+// 通过 SHAPE 扩展把输入区域设为空实现鼠标穿透，
+// 通过 _NET_WM_STATE 让窗口始终置顶且不出现在任务栏/分页器中。
+// 透明效果依赖合成管理器；在 Xvfb 下没有合成器也可以正常运行，只是背景不透明
+type x11Overlay struct {
+	conn  *xgb.Conn
+	root  xproto.Window
+	w, h  int
+	input *x11Input
+
+	// XFixes 扩展是否可用 (隐藏光标需要)，以及当前是否隐藏了光标
+	xfixes       bool
+	cursorHidden bool
+
+	// 关闭 stop 让维护协程退出，协程退出后关闭 done
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newOverlayBackend() (OverlayBackend, error) {
+	// 连接 $DISPLAY
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, fmt.Errorf("connect to X server: %w", err)
+	}
+	if err := shape.Init(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("X server lacks SHAPE extension: %w", err)
+	}
+
+	// 覆盖默认屏幕的根窗口，多显示器 (Xinerama/RandR) 下根窗口即为整个虚拟屏幕
+	screen := xproto.Setup(conn).DefaultScreen(conn)
+	w := int(screen.WidthInPixels)
+	h := int(screen.HeightInPixels)
+
+	// XFixes 不可用时只是不能隐藏光标
+	xfixesOK := xfixes.Init(conn) == nil
+	if xfixesOK {
+		_, err := xfixes.QueryVersion(conn, 4, 0).Reply()
+		xfixesOK = err == nil
+	}
+
+	return &x11Overlay{
+		conn:   conn,
+		root:   screen.Root,
+		w:      w,
+		h:      h,
+		input:  newX11Input(conn, screen.Root, w, h),
+		xfixes: xfixesOK,
+		stop:   make(chan struct{}),
+	}, nil
+}
+
+// Bounds 实现 OverlayBackend
+func (o *x11Overlay) Bounds() (x, y, w, h int) {
+	return 0, 0, o.w, o.h
+}
+
+// Input 实现 OverlayBackend
+func (o *x11Overlay) Input() InputSource {
+	return o.input
+}
+
+// Start 实现 OverlayBackend
+// 连接由 Close 在游戏循环结束后关闭，维护协程只在收到退出信号时停止
+func (o *x11Overlay) Start(quitChan chan struct{}) {
+	o.done = make(chan struct{})
+	go func() {
+		defer close(o.done)
+
+		// 尝试多次，以防窗口创建延迟
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+
+		var overlay xproto.Window
+		for overlay == 0 {
+			select {
+			case <-quitChan:
+				return
+			case <-o.stop:
+				return
+			case <-ticker.C:
+				overlay = o.findWindow(o.root, overlayTitle)
+			}
+		}
+
+		o.applyOverlayStyle(overlay)
+		o.input.setWindow(overlay)
+		log.Println("X11 overlay window configured for passthrough")
+
+		// 定期维护窗口置顶状态，防止被新打开的窗口遮挡
+		ticker.Reset(500 * time.Millisecond)
+		for {
+			select {
+			case <-quitChan:
+				return
+			case <-o.stop:
+				return
+			case <-ticker.C:
+				xproto.ConfigureWindow(o.conn, overlay, xproto.ConfigWindowStackMode,
+					[]uint32{xproto.StackModeAbove})
+			}
+		}
+	}()
+}
+
+// Close 实现 OverlayBackend
+// 等维护协程退出后再关闭连接，避免与 Poll、SetCursorHidden 同时使用连接
+func (o *x11Overlay) Close() {
+	close(o.stop)
+	if o.done != nil {
+		<-o.done
+	}
+	o.conn.Close()
+}
+
+// applyOverlayStyle 设置鼠标穿透、置顶并覆盖整个根窗口
+func (o *x11Overlay) applyOverlayStyle(overlay xproto.Window) {
+	// 空的输入区域：所有鼠标事件都穿透到下层窗口
+	shape.Rectangles(o.conn, shape.SoSet, shape.SkInput, xproto.ClipOrderingUnsorted,
+		overlay, 0, 0, nil)
+
+	// 窗口已映射，需要通过发给根窗口的 ClientMessage 修改 _NET_WM_STATE
+	const netWMStateAdd = 1
+	state := o.atom("_NET_WM_STATE")
+	for _, name := range []string{"_NET_WM_STATE_ABOVE", "_NET_WM_STATE_SKIP_TASKBAR", "_NET_WM_STATE_SKIP_PAGER"} {
+		ev := xproto.ClientMessageEvent{
+			Format: 32,
+			Window: overlay,
+			Type:   state,
+			Data:   xproto.ClientMessageDataUnionData32New([]uint32{netWMStateAdd, uint32(o.atom(name)), 0, 1, 0}),
+		}
+		xproto.SendEvent(o.conn, false, o.root,
+			xproto.EventMaskSubstructureRedirect|xproto.EventMaskSubstructureNotify, string(ev.Bytes()))
+	}
+
+	// 强制设置窗口位置和大小，覆盖整个根窗口
+	xproto.ConfigureWindow(o.conn, overlay,
+		xproto.ConfigWindowX|xproto.ConfigWindowY|xproto.ConfigWindowWidth|xproto.ConfigWindowHeight|xproto.ConfigWindowStackMode,
+		[]uint32{0, 0, uint32(o.w), uint32(o.h), xproto.StackModeAbove})
+}
+
+// findWindow 在窗口树中按标题 (_NET_WM_NAME 或 WM_NAME) 查找窗口
+func (o *x11Overlay) findWindow(parent xproto.Window, title string) xproto.Window {
+	tree, err := xproto.QueryTree(o.conn, parent).Reply()
+	if err != nil {
+		return 0
+	}
+	netWMName := o.atom("_NET_WM_NAME")
+	for _, child := range tree.Children {
+		for _, prop := range []xproto.Atom{netWMName, xproto.AtomWmName} {
+			reply, err := xproto.GetProperty(o.conn, false, child, prop,
+				xproto.GetPropertyTypeAny, 0, 256).Reply()
+			if err == nil && string(reply.Value) == title {
+				return child
+			}
+		}
+		if found := o.findWindow(child, title); found != 0 {
+			return found
+		}
+	}
+	return 0
+}
+
+func (o *x11Overlay) atom(name string) xproto.Atom {
+	reply, err := xproto.InternAtom(o.conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		return 0
+	}
+	return reply.Atom
+}
+
+// SetCursorHidden 实现 OverlayBackend
+// 通过 XFixes 在根窗口上隐藏光标，连接断开时 X 服务器会自动恢复
+func (o *x11Overlay) SetCursorHidden(hidden bool) {
+	if hidden == o.cursorHidden || !o.xfixes {
+		return
+	}
+	o.cursorHidden = hidden
+	if hidden {
+		xfixes.HideCursor(o.conn, o.root)
+	} else {
+		xfixes.ShowCursor(o.conn, o.root)
+	}
+}
+
+// SetKeyboardCapture 实现 OverlayBackend
+// 关闭时不再轮询键盘状态，重新开启时忽略关闭期间按住的键
+func (o *x11Overlay) SetKeyboardCapture(on bool) {
+	in := o.input
+	if on == in.captureKeys {
+		return
+	}
+	in.captureKeys = on
+	if on && in.keyboard != nil {
+		in.keyboard.poll(in.conn, 0)
+	}
+}
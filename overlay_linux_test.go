@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/shape"
+	"github.com/jezek/xgb/xproto"
+)
+
+// waitFor 轮询 cond 直到返回 true，超时后报告 what
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// TestX11Overlay 需要 X 服务器，例如 xvfb-run go test -run X11 .
+func TestX11Overlay(t *testing.T) {
+	if os.Getenv("DISPLAY") == "" {
+		t.Skip("DISPLAY is not set")
+	}
+	backend, err := newOverlayBackend()
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := backend.(*x11Overlay)
+
+	// 用另一个连接创建带覆盖窗口标题的窗口，代替 Ebiten 创建的窗口
+	conn, err := xgb.NewConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := shape.Init(conn); err != nil {
+		t.Fatal(err)
+	}
+	screen := xproto.Setup(conn).DefaultScreen(conn)
+	win, err := xproto.NewWindowId(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xproto.CreateWindow(conn, screen.RootDepth, win, screen.Root, 0, 0, 100, 100, 0,
+		xproto.WindowClassInputOutput, screen.RootVisual, 0, nil)
+	xproto.ChangeProperty(conn, xproto.PropModeReplace, win, xproto.AtomWmName, xproto.AtomString, 8,
+		uint32(len(overlayTitle)), []byte(overlayTitle))
+	if err := xproto.MapWindowChecked(conn, win).Check(); err != nil {
+		t.Fatal(err)
+	}
+
+	quit := make(chan struct{})
+	o.Start(quit)
+	defer func() {
+		close(quit)
+		o.Close()
+	}()
+
+	waitFor(t, "the overlay window to be found", func() bool {
+		return xproto.Window(o.input.window.Load()) == win
+	})
+
+	// 输入区域为空，鼠标事件全部穿透
+	waitFor(t, "an empty input region", func() bool {
+		reply, err := shape.GetRectangles(conn, win, shape.SkInput).Reply()
+		return err == nil && len(reply.Rectangles) == 0
+	})
+	// 窗口覆盖整个根窗口
+	waitFor(t, "the overlay to cover the root window", func() bool {
+		geom, err := xproto.GetGeometry(conn, xproto.Drawable(win)).Reply()
+		return err == nil && geom.X == 0 && geom.Y == 0 &&
+			int(geom.Width) == o.w && int(geom.Height) == o.h
+	})
+
+	// QueryPointer 读取的位置与根窗口坐标一致
+	if err := xproto.WarpPointerChecked(conn, 0, screen.Root, 0, 0, 0, 0, 37, 42).Check(); err != nil {
+		t.Fatal(err)
+	}
+	var state InputState
+	waitFor(t, "the pointer at (37, 42)", func() bool {
+		state = o.input.Poll()
+		return state.X == 37 && state.Y == 42
+	})
+	if state.Pressed(MouseButtonLeft) || state.Pressed(MouseButtonRight) {
+		t.Errorf("buttons reported pressed: %+v", state.Buttons)
+	}
+}
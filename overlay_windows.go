@@ -0,0 +1,233 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"github.com/lxn/win"
+)
+
+const (
+	GWL_EXSTYLE       = -20
+	WS_EX_TOOLWINDOW  = 0x00000080
+	WS_EX_APPWINDOW   = 0x00040000
+	WS_EX_LAYERED     = 0x00080000
+	WS_EX_TRANSPARENT = 0x00000020
+	SWP_NOSIZE        = 0x0001
+	SWP_NOMOVE        = 0x0002
+	SWP_NOZORDER      = 0x0004
+	SWP_FRAMECHANGED  = 0x0020
+	SWP_NOACTIVATE    = 0x0010
+	LWA_COLORKEY      = 0x00000001
+	LWA_ALPHA         = 0x00000002
+)
+
+var (
+	user32dll                        = syscall.NewLazyDLL("user32.dll")
+	procSetLayeredWindowAttributes   = user32dll.NewProc("SetLayeredWindowAttributes")
+	procGetAsyncKeyState             = user32dll.NewProc("GetAsyncKeyState")
+	dwmapi                           = syscall.NewLazyDLL("dwmapi.dll")
+	procDwmExtendFrameIntoClientArea = dwmapi.NewProc("DwmExtendFrameIntoClientArea")
+)
+
+type MARGINS struct {
+	CxLeftWidth    int32
+	CxRightWidth   int32
+	CyTopHeight    int32
+	CyBottomHeight int32
+}
+
+// win32Overlay 基于 Win32/DWM 的覆盖窗口后端
+type win32Overlay struct {
+	x, y, w, h   int
+	input        *win32Input
+	cursorHidden bool
+}
+
+func newOverlayBackend() (OverlayBackend, error) {
+	// 获取虚拟屏幕位置和尺寸
+	vx := int(win.GetSystemMetrics(win.SM_XVIRTUALSCREEN))
+	vy := int(win.GetSystemMetrics(win.SM_YVIRTUALSCREEN))
+	vw := int(win.GetSystemMetrics(win.SM_CXVIRTUALSCREEN))
+	vh := int(win.GetSystemMetrics(win.SM_CYVIRTUALSCREEN))
+
+	// Hack: 增加高度以避免 Windows 将其识别为独占全屏应用，从而导致 DWM 透明失效
+	// 特别是在单显示器环境下
+	vh += 1
+
+	// 上次运行在隐藏光标时被强制结束的话，系统光标仍然是透明的，启动时先从注册表恢复
+	// 只在留下了标记文件时恢复，不影响其他程序对光标的修改
+	if _, err := os.Stat(cursorMarkerPath()); err == nil {
+		log.Println("Restoring system cursors hidden by a previous run")
+		win.SystemParametersInfo(SPI_SETCURSORS, 0, nil, 0)
+		os.Remove(cursorMarkerPath())
+	}
+
+	return &win32Overlay{
+		x: vx, y: vy, w: vw, h: vh,
+		input: newWin32Input(vw, vh),
+	}, nil
+}
+
+// Bounds 实现 OverlayBackend
+func (o *win32Overlay) Bounds() (x, y, w, h int) {
+	return o.x, o.y, o.w, o.h
+}
+
+// Input 实现 OverlayBackend
+func (o *win32Overlay) Input() InputSource {
+	return o.input
+}
+
+// Start 实现 OverlayBackend
+func (o *win32Overlay) Start(quitChan chan struct{}) {
+	// 安装低级鼠标钩子以获取滚轮事件
+	go o.input.hooks.run(quitChan)
+
+	// 隐藏任务栏图标并强制全屏覆盖
+	go func() {
+		// 尝试多次，以防窗口创建延迟
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+
+		titlePtr := syscall.StringToUTF16Ptr(overlayTitle)
+		applyCount := 0
+
+		for range ticker.C {
+			// FindWindow 可能找不到，如果标题还没设置好。
+			// Ebiten 的默认类名不确定，所以用 nil
+			hwnd := win.FindWindow(nil, titlePtr)
+			if hwnd != 0 {
+				// 获取当前扩展样式
+				exStyle := win.GetWindowLong(hwnd, GWL_EXSTYLE)
+
+				// 添加 LAYERED 以确保 Windows 复合透明正常工作
+				// 移除 WS_EX_LAYERED，因为 DWM 玻璃效果不需要它，且它可能与 DX 冲突
+				newExStyle := (exStyle & ^WS_EX_APPWINDOW) | WS_EX_TOOLWINDOW | WS_EX_TRANSPARENT
+
+				if newExStyle != exStyle {
+					win.SetWindowLong(hwnd, GWL_EXSTYLE, newExStyle)
+					log.Println("Window style updated to hide from taskbar with passthrough")
+				}
+
+				// 移除 SetLayeredWindowAttributes 调用，因为它会破坏 DWM 玻璃效果
+
+				// 使用 DWM 扩展玻璃到整个客户端区域，避免黑底
+				m := MARGINS{-1, -1, -1, -1}
+				_, _, _ = procDwmExtendFrameIntoClientArea.Call(
+					uintptr(hwnd), uintptr(unsafe.Pointer(&m)),
+				)
+
+				// 强制设置窗口位置和大小，覆盖整个虚拟屏幕
+				// 即使 Ebiten/GLFW 试图限制它，我们也强制覆盖
+				// 使用 HWND_TOPMOST (-1) 确保窗口在最上层
+				// 移除 SWP_NOZORDER 以允许改变 Z 序
+				win.SetWindowPos(hwnd, win.HWND_TOPMOST, int32(o.x), int32(o.y), int32(o.w), int32(o.h),
+					SWP_NOACTIVATE|SWP_FRAMECHANGED)
+
+				applyCount++
+				if applyCount > 5 {
+					// 设置 HWND 给输入源
+					o.input.setWindow(hwnd)
+					// 再次检查确认
+					currentStyle := win.GetWindowLong(hwnd, GWL_EXSTYLE)
+					if currentStyle&WS_EX_TOOLWINDOW != 0 {
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	// 启动一个协程定期维护窗口置顶状态，防止被新打开的窗口遮挡
+	go func() {
+		// 等待初始化完成
+		time.Sleep(2 * time.Second)
+
+		ticker := time.NewTicker(100 * time.Millisecond) // 提高频率到 100ms
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-quitChan:
+				return
+			case <-ticker.C:
+				if hwnd := o.input.window(); hwnd != 0 {
+					// 仅维护 Z 序，不改变大小和位置
+					win.SetWindowPos(hwnd, win.HWND_TOPMOST, 0, 0, 0, 0,
+						SWP_NOMOVE|SWP_NOSIZE|SWP_NOACTIVATE)
+				}
+			}
+		}
+	}()
+}
+
+var (
+	procCreateCursor    = user32dll.NewProc("CreateCursor")
+	procCopyIcon        = user32dll.NewProc("CopyIcon")
+	procDestroyCursor   = user32dll.NewProc("DestroyCursor")
+	procSetSystemCursor = user32dll.NewProc("SetSystemCursor")
+)
+
+// SPI_SETCURSORS 从注册表重新加载系统光标
+const SPI_SETCURSORS = 0x0057
+
+// cursorMarkerPath 隐藏光标期间存在的标记文件，进程被强制结束后下次启动据此恢复光标
+func cursorMarkerPath() string {
+	return filepath.Join(os.TempDir(), "mouse_flow_cursor_hidden")
+}
+
+// systemCursorIDs 隐藏光标时替换的系统光标 (OCR_NORMAL、OCR_IBEAM、OCR_HAND 等)
+var systemCursorIDs = []uint32{32512, 32513, 32514, 32515, 32516, 32642, 32643, 32644, 32645, 32646, 32648, 32649, 32650}
+
+// SetCursorHidden 实现 OverlayBackend
+// 用全透明的光标替换系统光标，恢复时从注册表重新加载用户的光标方案
+func (o *win32Overlay) SetCursorHidden(hidden bool) {
+	if hidden == o.cursorHidden {
+		return
+	}
+	o.cursorHidden = hidden
+	if !hidden {
+		win.SystemParametersInfo(SPI_SETCURSORS, 0, nil, 0)
+		os.Remove(cursorMarkerPath())
+		return
+	}
+
+	// 先写标记文件再替换光标，替换后任何时候被结束都能在下次启动时恢复
+	if err := os.WriteFile(cursorMarkerPath(), nil, 0o644); err != nil {
+		log.Println("Failed to write cursor marker:", err)
+	}
+
+	// 32x32 单色光标：AND 掩码全 1、XOR 掩码全 0 即完全透明
+	var and, xor [32 * 32 / 8]byte
+	for i := range and {
+		and[i] = 0xFF
+	}
+	blank, _, _ := procCreateCursor.Call(0, 0, 0, 32, 32,
+		uintptr(unsafe.Pointer(&and[0])), uintptr(unsafe.Pointer(&xor[0])))
+	if blank == 0 {
+		log.Println("Failed to create blank cursor")
+		return
+	}
+	defer procDestroyCursor.Call(blank)
+	// SetSystemCursor 会销毁传入的光标，每个 ID 使用一份拷贝
+	for _, id := range systemCursorIDs {
+		if c, _, _ := procCopyIcon.Call(blank); c != 0 {
+			procSetSystemCursor.Call(c, uintptr(id))
+		}
+	}
+}
+
+// SetKeyboardCapture 实现 OverlayBackend
+// 按需安装或移除全局键盘钩子，不显示按键时不监听键盘
+func (o *win32Overlay) SetKeyboardCapture(on bool) {
+	o.input.hooks.setKeyboard(on)
+}
+
+// Close 实现 OverlayBackend
+// 钩子和维护协程随退出信号停止，没有需要释放的连接
+func (o *win32Overlay) Close() {}
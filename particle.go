@@ -0,0 +1,378 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// 粒子发射器的挂载位置
+const (
+	ParticleAttachHead  = "head"  // 光标头部，移动时持续发射
+	ParticleAttachClick = "click" // 点击时一次性喷发
+	ParticleAttachTrail = "trail" // 沿轨迹随机位置持续发射
+)
+
+// 粒子预设
+const (
+	ParticleSparkle  = "sparkle"
+	ParticleEmber    = "ember"
+	ParticleSmoke    = "smoke"
+	ParticleConfetti = "confetti"
+)
+
+// 粒子形状
+const (
+	ParticleCircle = "circle"
+	ParticleSquare = "square" // 会按 Spin 旋转，适合彩纸
+)
+
+// 粒子颜色模式
+const (
+	ParticleColorLife   = "life"   // 颜色随寿命沿 Colors 渐变
+	ParticleColorRandom = "random" // 出生时在 Colors 渐变上随机取一个颜色，寿命末尾淡出
+)
+
+const (
+	// 粒子总数上限，限制每帧的模拟和绘制开销
+	maxParticles = 1500
+	// 圆形粒子的边数
+	particleSegments = 8
+)
+
+// EmitterConfig 粒子发射器
+// 设置了 Preset 时，值为 0 (或空) 的字段使用预设的值
+type EmitterConfig struct {
+	Enabled  bool    `json:"enabled"`
+	Preset   string  `json:"preset"`    // "sparkle", "ember", "smoke", "confetti"
+	Attach   string  `json:"attach"`    // "head", "click", "trail"
+	Rate     float64 `json:"rate"`      // 每秒发射数量 (head / trail)
+	Burst    int     `json:"burst"`     // 每次点击发射数量 (click)
+	MinSpeed float64 `json:"min_speed"` // head 模式下光标速度 (像素/秒) 高于该值才发射
+
+	Lifetime  [2]float64 `json:"lifetime"`  // 寿命范围 (秒)
+	Speed     [2]float64 `json:"speed"`     // 初速度范围 (像素/秒)
+	Direction float64    `json:"direction"` // 发射方向 (度，0 = 向右，-90 = 向上)
+	Spread    float64    `json:"spread"`    // 发射角度范围 (度，360 = 全方向)
+	Inherit   float64    `json:"inherit"`   // 继承光标速度的比例
+	Gravity   float64    `json:"gravity"`   // 重力加速度 (像素/秒²，正数向下)
+	Drag      float64    `json:"drag"`      // 阻力系数 (1/秒)
+
+	Size      [2]float64     `json:"size"`       // 出生和消亡时的半径 (像素)
+	Colors    []GradientStop `json:"colors"`     // 颜色渐变，为空时使用轨迹颜色
+	ColorMode string         `json:"color_mode"` // "life", "random"
+	Shape     string         `json:"shape"`      // "circle", "square"
+	Spin      float64        `json:"spin"`       // 最大旋转速度 (弧度/秒)
+}
+
+// particlePreset 返回预设的发射器参数
+func particlePreset(name string) EmitterConfig {
+	switch name {
+	case ParticleEmber:
+		return EmitterConfig{
+			Preset: name, Attach: ParticleAttachTrail, Rate: 40,
+			Lifetime: [2]float64{0.6, 1.2}, Speed: [2]float64{10, 40}, Direction: -90, Spread: 60,
+			Gravity: -60, Drag: 1.5, Size: [2]float64{2.5, 0.5},
+			Colors: []GradientStop{
+				{Pos: 0, Color: [4]uint8{255, 230, 120, 255}},
+				{Pos: 0.4, Color: [4]uint8{255, 120, 20, 230}},
+				{Pos: 1, Color: [4]uint8{160, 20, 0, 0}},
+			},
+			ColorMode: ParticleColorLife, Shape: ParticleCircle,
+		}
+	case ParticleSmoke:
+		return EmitterConfig{
+			Preset: name, Attach: ParticleAttachHead, Rate: 30, MinSpeed: 30,
+			Lifetime: [2]float64{0.8, 1.6}, Speed: [2]float64{5, 25}, Direction: -90, Spread: 90,
+			Inherit: 0.1, Gravity: -30, Drag: 2, Size: [2]float64{3, 14},
+			Colors: []GradientStop{
+				{Pos: 0, Color: [4]uint8{200, 200, 200, 90}},
+				{Pos: 1, Color: [4]uint8{120, 120, 120, 0}},
+			},
+			ColorMode: ParticleColorLife, Shape: ParticleCircle,
+		}
+	case ParticleConfetti:
+		return EmitterConfig{
+			Preset: name, Attach: ParticleAttachClick, Burst: 30,
+			Lifetime: [2]float64{0.8, 1.5}, Speed: [2]float64{150, 400}, Direction: -90, Spread: 120,
+			Gravity: 600, Drag: 2.5, Size: [2]float64{4, 4},
+			Colors: []GradientStop{
+				{Pos: 0, Color: [4]uint8{255, 60, 80, 255}},
+				{Pos: 0.25, Color: [4]uint8{255, 200, 0, 255}},
+				{Pos: 0.5, Color: [4]uint8{40, 200, 90, 255}},
+				{Pos: 0.75, Color: [4]uint8{40, 140, 255, 255}},
+				{Pos: 1, Color: [4]uint8{200, 80, 255, 255}},
+			},
+			ColorMode: ParticleColorRandom, Shape: ParticleSquare, Spin: 12,
+		}
+	default: // sparkle
+		return EmitterConfig{
+			Preset: ParticleSparkle, Attach: ParticleAttachHead, Rate: 60, MinSpeed: 50,
+			Lifetime: [2]float64{0.2, 0.5}, Speed: [2]float64{30, 120}, Spread: 360,
+			Inherit: -0.1, Drag: 3, Size: [2]float64{2, 0},
+			Colors: []GradientStop{
+				{Pos: 0, Color: [4]uint8{255, 255, 255, 255}},
+				{Pos: 1, Color: [4]uint8{255, 230, 150, 0}},
+			},
+			ColorMode: ParticleColorLife, Shape: ParticleCircle,
+		}
+	}
+}
+
+// normalize 用预设补全未设置的字段
+func (e *EmitterConfig) normalize() {
+	if e.Preset == "" {
+		if e.Attach == "" {
+			e.Attach = ParticleAttachHead
+		}
+		return
+	}
+	p := particlePreset(e.Preset)
+	if e.Attach == "" {
+		e.Attach = p.Attach
+	}
+	if e.Rate == 0 {
+		e.Rate = p.Rate
+	}
+	if e.Burst == 0 {
+		e.Burst = p.Burst
+	}
+	if e.MinSpeed == 0 {
+		e.MinSpeed = p.MinSpeed
+	}
+	if e.Lifetime == [2]float64{} {
+		e.Lifetime = p.Lifetime
+	}
+	if e.Speed == [2]float64{} {
+		e.Speed = p.Speed
+	}
+	if e.Direction == 0 {
+		e.Direction = p.Direction
+	}
+	if e.Spread == 0 {
+		e.Spread = p.Spread
+	}
+	if e.Inherit == 0 {
+		e.Inherit = p.Inherit
+	}
+	if e.Gravity == 0 {
+		e.Gravity = p.Gravity
+	}
+	if e.Drag == 0 {
+		e.Drag = p.Drag
+	}
+	if e.Size == [2]float64{} {
+		e.Size = p.Size
+	}
+	if len(e.Colors) == 0 {
+		e.Colors = p.Colors
+	}
+	if e.ColorMode == "" {
+		e.ColorMode = p.ColorMode
+	}
+	if e.Shape == "" {
+		e.Shape = p.Shape
+	}
+	if e.Spin == 0 {
+		e.Spin = p.Spin
+	}
+}
+
+// particle 单个粒子
+type particle struct {
+	X, Y    float64
+	VX, VY  float64
+	Age     float64 // 已存活时间 (秒)
+	Life    float64 // 寿命 (秒)
+	Rot     float64 // 旋转角度 (square)
+	Spin    float64
+	Hue     float64 // random 颜色模式下在渐变上的位置
+	Emitter int     // 所属发射器在 Config.Particles 中的序号
+}
+
+// ParticleSystem 所有发射器产生的粒子
+type ParticleSystem struct {
+	particles []particle
+	carry     []float64  // 每个发射器未满一个粒子的发射量
+	gradients []gradient // 每个发射器的颜色渐变缓存
+	rng       *rand.Rand
+}
+
+func newParticleSystem() ParticleSystem {
+	// 固定种子，脚本回放的结果可重复
+	return ParticleSystem{rng: rand.New(rand.NewPCG(1, 2))}
+}
+
+// emit 按发射器 e 在 (x, y) 发射一个粒子，(vx, vy) 为光标速度
+func (ps *ParticleSystem) emit(idx int, e *EmitterConfig, x, y, vx, vy float64) {
+	if len(ps.particles) >= maxParticles {
+		return
+	}
+	angle := (e.Direction + (ps.rng.Float64()-0.5)*e.Spread) * math.Pi / 180
+	speed := lerp(e.Speed[0], e.Speed[1], ps.rng.Float64())
+	sin, cos := math.Sincos(angle)
+	ps.particles = append(ps.particles, particle{
+		X:       x,
+		Y:       y,
+		VX:      cos*speed + vx*e.Inherit,
+		VY:      sin*speed + vy*e.Inherit,
+		Life:    math.Max(0.01, lerp(e.Lifetime[0], e.Lifetime[1], ps.rng.Float64())),
+		Rot:     ps.rng.Float64() * 2 * math.Pi,
+		Spin:    (ps.rng.Float64()*2 - 1) * e.Spin,
+		Hue:     ps.rng.Float64(),
+		Emitter: idx,
+	})
+}
+
+// Burst 在点击位置触发所有 click 发射器
+func (ps *ParticleSystem) Burst(emitters []EmitterConfig, x, y float64) {
+	for i := range emitters {
+		e := &emitters[i]
+		if !e.Enabled || e.Attach != ParticleAttachClick {
+			continue
+		}
+		for n := 0; n < e.Burst; n++ {
+			ps.emit(i, e, x, y, 0, 0)
+		}
+	}
+}
+
+// Update 推进 dt 秒：持续发射、运动积分并移除死亡粒子
+// (x, y, vx, vy) 为光标位置和速度，trail 为当前轨迹点
+func (ps *ParticleSystem) Update(emitters []EmitterConfig, dt, x, y, vx, vy float64, trail []TracePoint) bool {
+	if len(ps.carry) < len(emitters) {
+		ps.carry = append(ps.carry, make([]float64, len(emitters)-len(ps.carry))...)
+	}
+
+	speed := math.Hypot(vx, vy)
+	for i := range emitters {
+		e := &emitters[i]
+		if !e.Enabled || e.Rate <= 0 {
+			continue
+		}
+		switch {
+		case e.Attach == ParticleAttachHead && speed >= e.MinSpeed:
+		case e.Attach == ParticleAttachTrail && len(trail) >= 2:
+		default:
+			ps.carry[i] = 0
+			continue
+		}
+
+		ps.carry[i] += e.Rate * dt
+		for ; ps.carry[i] >= 1; ps.carry[i]-- {
+			if e.Attach == ParticleAttachTrail {
+				p := trail[ps.rng.IntN(len(trail))]
+				ps.emit(i, e, p.X, p.Y, vx, vy)
+			} else {
+				ps.emit(i, e, x, y, vx, vy)
+			}
+		}
+	}
+
+	alive := 0
+	for i := range ps.particles {
+		p := &ps.particles[i]
+		p.Age += dt
+		if p.Age >= p.Life || p.Emitter >= len(emitters) {
+			continue
+		}
+		e := &emitters[p.Emitter]
+		// 线性阻力用指数衰减，与帧率无关
+		damp := math.Exp(-e.Drag * dt)
+		p.VX *= damp
+		p.VY = p.VY*damp + e.Gravity*dt
+		p.X += p.VX * dt
+		p.Y += p.VY * dt
+		p.Rot += p.Spin * dt
+
+		ps.particles[alive] = *p
+		alive++
+	}
+	ps.particles = ps.particles[:alive]
+	return alive > 0
+}
+
+// buildParticleGeometry 生成粒子的三角形，与轨迹合并到同一次 DrawTriangles32
+func (tm *TraceManager) buildParticleGeometry() {
+	ps := &tm.particles
+	if len(ps.particles) == 0 {
+		return
+	}
+
+	emitters := tm.config.Particles
+	for len(ps.gradients) < len(emitters) {
+		ps.gradients = append(ps.gradients, nil)
+	}
+	for i := range emitters {
+		ps.gradients[i] = newGradient(ps.gradients[i], emitters[i].Colors)
+	}
+
+	baseR, baseG, baseB, baseA := tm.baseColor()
+	for _, p := range ps.particles {
+		// 发射器被删除后，剩余的粒子在下一次 Update 时移除
+		if p.Emitter >= len(emitters) {
+			continue
+		}
+		e := &emitters[p.Emitter]
+		t := p.Age / p.Life
+
+		var r, g, b, a float64
+		switch {
+		case len(e.Colors) == 0:
+			// 使用轨迹颜色，随寿命淡出
+			r, g, b, a = float64(baseR), float64(baseG), float64(baseB), float64(baseA)*(1-t)
+		case e.ColorMode == ParticleColorRandom:
+			r, g, b, a = ps.gradients[p.Emitter].At(p.Hue)
+			a *= math.Min(1, (1-t)*4) // 寿命最后 1/4 淡出
+		default:
+			r, g, b, a = ps.gradients[p.Emitter].At(t)
+		}
+		if a <= 0 {
+			continue
+		}
+		c := vertexColor{float32(r * a), float32(g * a), float32(b * a), float32(a)}
+
+		size := lerp(e.Size[0], e.Size[1], t)
+		if size < 0.3 {
+			continue
+		}
+		if e.Shape == ParticleSquare {
+			tm.addQuad(p.X, p.Y, size, p.Rot, c)
+		} else {
+			tm.addDisc(p.X, p.Y, size, particleSegments, c)
+		}
+	}
+}
+
+// addDisc 添加一个 segments 边形近似的实心圆
+func (tm *TraceManager) addDisc(x, y, radius float64, segments int, c vertexColor) {
+	center := uint32(len(tm.vertices))
+	tm.vertices = append(tm.vertices, ebitenVertex(x, y, c))
+	for i := 0; i < segments; i++ {
+		sin, cos := math.Sincos(float64(i) * 2 * math.Pi / float64(segments))
+		tm.vertices = append(tm.vertices, ebitenVertex(x+radius*cos, y+radius*sin, c))
+	}
+	for i := 0; i < segments; i++ {
+		next := (i + 1) % segments
+		tm.indices = append(tm.indices, center, center+1+uint32(i), center+1+uint32(next))
+	}
+}
+
+// addQuad 添加一个以 (x, y) 为中心、旋转 rot 的正方形，half 为半边长
+func (tm *TraceManager) addQuad(x, y, half, rot float64, c vertexColor) {
+	sin, cos := math.Sincos(rot)
+	ux, uy := cos*half, sin*half
+	vx, vy := -sin*half, cos*half
+
+	idx := uint32(len(tm.vertices))
+	tm.vertices = append(tm.vertices,
+		ebitenVertex(x-ux-vx, y-uy-vy, c),
+		ebitenVertex(x+ux-vx, y+uy-vy, c),
+		ebitenVertex(x-ux+vx, y-uy+vy, c),
+		ebitenVertex(x+ux+vx, y+uy+vy, c),
+	)
+	tm.indices = append(tm.indices, idx, idx+1, idx+2, idx+1, idx+3, idx+2)
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
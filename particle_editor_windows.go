@@ -0,0 +1,62 @@
+package main
+
+import (
+	"github.com/lxn/walk"
+	. "github.com/lxn/walk/declarative"
+)
+
+// particlePresets 配置窗口中可以直接开关的粒子预设
+var particlePresets = []struct {
+	preset string
+	key    string
+}{
+	{ParticleSparkle, "ParticleSparkle"},
+	{ParticleEmber, "ParticleEmber"},
+	{ParticleSmoke, "ParticleSmoke"},
+	{ParticleConfetti, "ParticleConfetti"},
+}
+
+// particleWidget 返回粒子预设开关的声明式控件
+// 每个预设对应 cfg.Particles 中 Preset 相同的第一个发射器，详细参数在 config.json 中调整
+func particleWidget(cfg *Config, onChange func()) Widget {
+	find := func(preset string) *EmitterConfig {
+		for i := range cfg.Particles {
+			if cfg.Particles[i].Preset == preset {
+				return &cfg.Particles[i]
+			}
+		}
+		return nil
+	}
+
+	children := make([]Widget, 0, len(particlePresets)+1)
+	for _, p := range particlePresets {
+		var box *walk.CheckBox
+		preset := p.preset
+		e := find(preset)
+		children = append(children, CheckBox{
+			AssignTo: &box,
+			Text:     T(p.key),
+			Checked:  e != nil && e.Enabled,
+			OnCheckedChanged: func() {
+				e := find(preset)
+				if e == nil {
+					if !box.Checked() {
+						return
+					}
+					// 首次开启时按预设添加发射器
+					cfg.Particles = append(cfg.Particles, particlePreset(preset))
+					e = &cfg.Particles[len(cfg.Particles)-1]
+				}
+				e.Enabled = box.Checked()
+				onChange()
+			},
+		})
+	}
+	children = append(children, Label{Text: T("ParticleHint")})
+
+	return GroupBox{
+		Title:    T("Particles"),
+		Layout:   VBox{},
+		Children: children,
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// 轨迹宽度/透明度曲线形状
+const (
+	ProfileLinear   = "linear"   // 线性渐变 (旧版行为)
+	ProfileConstant = "constant" // 恒定
+	ProfileEaseIn   = "ease_in"  // 先慢后快，尾部迅速变细
+	ProfileEaseOut  = "ease_out" // 先快后慢，大部分保持饱满
+	ProfileBlade    = "blade"    // 刀光：头尾尖、中间鼓，类似水果忍者
+	ProfileCustom   = "custom"   // 自定义控制点
+)
+
+// Profile 沿轨迹变化的曲线
+// 输入 t 为点的生命值：1 = 头部 (最新的点)，0 = 尾端 (即将消失)
+// 输出为乘在 TailWidth 或 TailColor alpha 上的系数
+type Profile struct {
+	Shape string `json:"shape"`
+	// 自定义控制点 [t, 值]，仅 Shape 为 "custom" 时使用，按 t 线性插值
+	Points [][2]float64 `json:"points,omitempty"`
+}
+
+// Eval 计算曲线在 t 处的值
+func (p Profile) Eval(t float64) float64 {
+	t = math.Max(0, math.Min(1, t))
+	switch p.Shape {
+	case ProfileConstant:
+		return 1
+	case ProfileEaseIn:
+		return t * t
+	case ProfileEaseOut:
+		return 1 - (1-t)*(1-t)
+	case ProfileBlade:
+		// 峰值约在 t = 0.63 处，头部收成尖角
+		return math.Sin(math.Pi * math.Pow(t, 1.5))
+	case ProfileCustom:
+		return p.evalCustom(t)
+	default:
+		return t
+	}
+}
+
+// evalCustom 按控制点分段线性插值，控制点范围外取端点值
+func (p Profile) evalCustom(t float64) float64 {
+	pts := p.Points
+	if len(pts) == 0 {
+		return t
+	}
+	if t <= pts[0][0] {
+		return pts[0][1]
+	}
+	for i := 1; i < len(pts); i++ {
+		if t <= pts[i][0] {
+			a, b := pts[i-1], pts[i]
+			if b[0] == a[0] {
+				return b[1]
+			}
+			return a[1] + (b[1]-a[1])*(t-a[0])/(b[0]-a[0])
+		}
+	}
+	return pts[len(pts)-1][1]
+}
+
+// normalize 补全缺省形状并把自定义控制点按 t 排序
+func (p *Profile) normalize() {
+	if p.Shape == "" {
+		p.Shape = ProfileLinear
+	}
+	sort.Slice(p.Points, func(i, j int) bool {
+		return p.Points[i][0] < p.Points[j][0]
+	})
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestProfileShapes(t *testing.T) {
+	tests := []struct {
+		shape string
+		t     float64
+		want  float64
+	}{
+		{ProfileLinear, 0, 0},
+		{ProfileLinear, 0.3, 0.3},
+		{ProfileLinear, 1, 1},
+		{"", 0.3, 0.3}, // 未知形状按线性处理
+		{ProfileConstant, 0, 1},
+		{ProfileConstant, 0.5, 1},
+		{ProfileEaseIn, 0.5, 0.25},
+		{ProfileEaseIn, 1, 1},
+		{ProfileEaseOut, 0.5, 0.75},
+		{ProfileEaseOut, 0, 0},
+		{ProfileBlade, 0, 0},
+		{ProfileBlade, 1, 0},
+		{ProfileBlade, math.Pow(0.5, 1/1.5), 1},
+		{ProfileCustom, 0.4, 0.4}, // 没有控制点时按线性处理
+		// t 超出 0-1 时截断
+		{ProfileLinear, -1, 0},
+		{ProfileLinear, 2, 1},
+		{ProfileEaseIn, 3, 1},
+	}
+	for _, tt := range tests {
+		p := Profile{Shape: tt.shape}
+		if got := p.Eval(tt.t); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%q.Eval(%v) = %v, want %v", tt.shape, tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestProfileCustom(t *testing.T) {
+	// 控制点故意乱序，normalize 后按 t 排序
+	p := Profile{Shape: ProfileCustom, Points: [][2]float64{{0.8, 0.4}, {0.2, 0.1}, {0.5, 1}}}
+	p.normalize()
+
+	tests := []struct {
+		t, want float64
+	}{
+		// 控制点之外取端点值
+		{-1, 0.1},
+		{0, 0.1},
+		{0.1, 0.1},
+		{0.9, 0.4},
+		{1, 0.4},
+		{2, 0.4},
+		// 经过控制点
+		{0.2, 0.1},
+		{0.5, 1},
+		{0.8, 0.4},
+		// 控制点之间线性插值
+		{0.35, 0.55},
+		{0.65, 0.7},
+		{0.275, 0.325},
+	}
+	for _, tt := range tests {
+		if got := p.Eval(tt.t); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Eval(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestProfileCustomStep(t *testing.T) {
+	// t 相同的两个控制点形成阶跃，该点取前一个控制点的值
+	p := Profile{Shape: ProfileCustom, Points: [][2]float64{{0, 0}, {0.5, 0.2}, {0.5, 0.9}, {1, 1}}}
+	tests := []struct {
+		t, want float64
+	}{
+		{0.25, 0.1},
+		{0.5, 0.2},
+		{0.75, 0.95},
+	}
+	for _, tt := range tests {
+		if got := p.Eval(tt.t); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Eval(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+}
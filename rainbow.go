@@ -0,0 +1,80 @@
+package main
+
+import (
+	"math"
+)
+
+// 彩虹模式
+const (
+	RainbowUniform = "uniform" // 整条轨迹同一色相，随时间循环
+	RainbowTrail   = "trail"   // 色相沿轨迹从头到尾展开
+	RainbowSpeed   = "speed"   // 移动越快色相循环越快
+)
+
+// 彩虹色彩空间
+const (
+	RainbowOKLCH = "oklch" // 感知均匀，各色相亮度一致
+	RainbowHSV   = "hsv"   // 传统 HSV 全饱和色
+)
+
+const (
+	// OKLCH 彩虹的亮度和彩度，取值保证大部分色相都在 sRGB 色域内
+	rainbowLightness = 0.75
+	rainbowChroma    = 0.14
+	// speed 模式下，达到该速度 (像素/秒) 时循环速度翻倍
+	rainbowSpeedRef = 1000.0
+)
+
+// Rainbow 彩虹模式的运行时状态
+// 只计算渲染用的颜色，不修改 Config.TailColor，保存配置时不会写入中间颜色
+type Rainbow struct {
+	phase float64 // 当前色相 0-1
+}
+
+// Advance 推进 dt 秒，speed 为光标移动速度 (像素/秒)
+func (rb *Rainbow) Advance(cfg *Config, dt, speed float64) {
+	if cfg.RainbowPeriod <= 0 {
+		return
+	}
+	rate := 1 / cfg.RainbowPeriod
+	if cfg.RainbowMode == RainbowSpeed {
+		rate *= 1 + speed/rainbowSpeedRef
+	}
+	rb.phase = math.Mod(rb.phase+rate*dt, 1)
+}
+
+// At 返回轨迹位置 u (0 = 头部，1 = 尾端) 处的颜色 (sRGB，0-1)
+func (rb *Rainbow) At(cfg *Config, u float64) (r, g, b float64) {
+	hue := rb.phase
+	if cfg.RainbowMode == RainbowTrail {
+		hue += u * cfg.RainbowSpread
+	}
+	hue -= math.Floor(hue)
+	return hueToRGB(cfg.RainbowSpace, hue)
+}
+
+// hueToRGB 把色相 (0-1) 转换为 sRGB 颜色
+func hueToRGB(space string, hue float64) (r, g, b float64) {
+	if space == RainbowHSV {
+		// 饱和度和明度均为 1 的 HSV
+		h := hue * 6
+		x := 1 - math.Abs(math.Mod(h, 2)-1)
+		switch int(h) % 6 {
+		case 0:
+			return 1, x, 0
+		case 1:
+			return x, 1, 0
+		case 2:
+			return 0, 1, x
+		case 3:
+			return 0, x, 1
+		case 4:
+			return x, 0, 1
+		default:
+			return 1, 0, x
+		}
+	}
+
+	sin, cos := math.Sincos(hue * 2 * math.Pi)
+	return oklab{L: rainbowLightness, A: rainbowChroma * cos, B: rainbowChroma * sin}.toSRGB()
+}
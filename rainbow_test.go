@@ -0,0 +1,116 @@
+package main
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestHueToRGBHSV(t *testing.T) {
+	tests := []struct {
+		hue     float64
+		r, g, b float64
+	}{
+		{0, 1, 0, 0},
+		{1.0 / 12, 1, 0.5, 0},
+		{1.0 / 6, 1, 1, 0},
+		{1.0 / 3, 0, 1, 0},
+		{0.5, 0, 1, 1},
+		{2.0 / 3, 0, 0, 1},
+		{5.0 / 6, 1, 0, 1},
+		{11.0 / 12, 1, 0, 0.5},
+	}
+	for _, tt := range tests {
+		r, g, b := hueToRGB(RainbowHSV, tt.hue)
+		if math.Abs(r-tt.r) > 1e-9 || math.Abs(g-tt.g) > 1e-9 || math.Abs(b-tt.b) > 1e-9 {
+			t.Errorf("hue %v = (%v, %v, %v), want (%v, %v, %v)", tt.hue, r, g, b, tt.r, tt.g, tt.b)
+		}
+	}
+}
+
+func TestHueToRGBOklch(t *testing.T) {
+	// 各色相的 OKLab 亮度一致，在色域内的颜色转换回来时亮度不变
+	for i := 0; i < 12; i++ {
+		hue := float64(i) / 12
+		r, g, b := hueToRGB(RainbowOKLCH, hue)
+		for _, v := range []float64{r, g, b} {
+			if v < 0 || v > 1 {
+				t.Fatalf("hue %v = (%v, %v, %v), out of range", hue, r, g, b)
+			}
+		}
+		if l := srgbToOklab(r, g, b).L; math.Abs(l-rainbowLightness) > 0.02 {
+			t.Errorf("hue %v lightness = %v, want %v", hue, l, rainbowLightness)
+		}
+	}
+}
+
+func TestRainbowAdvance(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   string
+		period float64
+		dt     float64
+		speed  float64
+		want   float64
+	}{
+		{"uniform", RainbowUniform, 2, 0.5, 0, 0.25},
+		{"speed ignored outside speed mode", RainbowTrail, 2, 0.5, 5000, 0.25},
+		{"wraps around", RainbowUniform, 2, 2.5, 0, 0.25},
+		{"speed doubles the rate", RainbowSpeed, 2, 0.5, rainbowSpeedRef, 0.5},
+		{"idle in speed mode", RainbowSpeed, 2, 0.5, 0, 0.25},
+		{"zero period", RainbowUniform, 0, 0.5, 0, 0},
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		cfg.RainbowMode = tt.mode
+		cfg.RainbowPeriod = tt.period
+		var rb Rainbow
+		rb.Advance(cfg, tt.dt, tt.speed)
+		if math.Abs(rb.phase-tt.want) > 1e-9 {
+			t.Errorf("%s: phase = %v, want %v", tt.name, rb.phase, tt.want)
+		}
+	}
+}
+
+func TestRainbowAt(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   string
+		spread float64
+		phase  float64
+		u      float64
+		hue    float64
+	}{
+		{"uniform ignores position", RainbowUniform, 1, 0.2, 0.7, 0.2},
+		{"trail head", RainbowTrail, 1, 0.2, 0, 0.2},
+		{"trail spread", RainbowTrail, 0.5, 0.2, 0.6, 0.5},
+		{"trail wraps", RainbowTrail, 1, 0.75, 0.5, 0.25},
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		cfg.RainbowMode = tt.mode
+		cfg.RainbowSpread = tt.spread
+		cfg.RainbowSpace = RainbowHSV
+		rb := Rainbow{phase: tt.phase}
+		r, g, b := rb.At(cfg, tt.u)
+		wr, wg, wb := hueToRGB(RainbowHSV, tt.hue)
+		if math.Abs(r-wr) > 1e-9 || math.Abs(g-wg) > 1e-9 || math.Abs(b-wb) > 1e-9 {
+			t.Errorf("%s: At(%v) = (%v, %v, %v), want hue %v (%v, %v, %v)", tt.name, tt.u, r, g, b, tt.hue, wr, wg, wb)
+		}
+	}
+}
+
+func TestRainbowKeepsTailColor(t *testing.T) {
+	// 彩虹颜色只用于渲染，移动和绘制轨迹后保存的轨迹颜色不变
+	cfg := DefaultConfig()
+	cfg.IsRainbow = true
+	want := cfg.TailColor
+	in := NewScriptedInput()
+	in.MoveTo(120, 80, 30)
+	g := newScriptedGame(cfg, in)
+	runScript(g, in)
+	g.traceManager.DrawRGBA(image.NewRGBA(image.Rect(0, 0, 160, 120)))
+	if cfg.TailColor != want {
+		t.Errorf("tail color = %v, want %v", cfg.TailColor, want)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// RasterizeTriangles 纯软件光栅化，把 DrawTriangles32 使用的顶点/索引画到 dst 上
+// 颜色按顶点插值 (顶点颜色是预乘 alpha 的)，采样点为像素中心，
+// 混合方式与 TraceManager.Draw 一致：逐通道取 Max
+// 只支持纯色几何 (即采样 whiteImage 的情况)，忽略 SrcX/SrcY
+func RasterizeTriangles(dst *image.RGBA, vertices []ebiten.Vertex, indices []uint32) {
+	RasterizeTexturedTriangles(dst, vertices, indices, nil)
+}
+
+// RasterizeTexturedTriangles 与 RasterizeTriangles 相同，但颜色为纹理 tex 在 (SrcX, SrcY) 处的
+// 颜色 (最近邻采样，横向重复、纵向截断) 乘以顶点颜色，tex 为 nil 时等同于纯色
+func RasterizeTexturedTriangles(dst *image.RGBA, vertices []ebiten.Vertex, indices []uint32, tex *image.RGBA) {
+	bounds := dst.Bounds()
+	for i := 0; i+2 < len(indices); i += 3 {
+		rasterizeTriangle(dst, bounds,
+			&vertices[indices[i]], &vertices[indices[i+1]], &vertices[indices[i+2]], tex)
+	}
+}
+
+func rasterizeTriangle(dst *image.RGBA, bounds image.Rectangle, v0, v1, v2 *ebiten.Vertex, tex *image.RGBA) {
+	x0, y0 := float64(v0.DstX), float64(v0.DstY)
+	x1, y1 := float64(v1.DstX), float64(v1.DstY)
+	x2, y2 := float64(v2.DstX), float64(v2.DstY)
+
+	// 有向面积，为 0 表示退化三角形
+	area := (x1-x0)*(y2-y0) - (x2-x0)*(y1-y0)
+	if area == 0 {
+		return
+	}
+
+	// 包围盒，裁剪到目标图像
+	minX := max(int(math.Floor(min(x0, x1, x2))), bounds.Min.X)
+	maxX := min(int(math.Ceil(max(x0, x1, x2))), bounds.Max.X-1)
+	minY := max(int(math.Floor(min(y0, y1, y2))), bounds.Min.Y)
+	maxY := min(int(math.Ceil(max(y0, y1, y2))), bounds.Max.Y-1)
+	if minX > maxX || minY > maxY {
+		return
+	}
+
+	inv := 1 / area
+	for py := minY; py <= maxY; py++ {
+		cy := float64(py) + 0.5
+		for px := minX; px <= maxX; px++ {
+			cx := float64(px) + 0.5
+
+			// 重心坐标
+			w0 := ((x1-cx)*(y2-cy) - (x2-cx)*(y1-cy)) * inv
+			w1 := ((x2-cx)*(y0-cy) - (x0-cx)*(y2-cy)) * inv
+			w2 := 1 - w0 - w1
+			if w0 < 0 || w1 < 0 || w2 < 0 {
+				continue
+			}
+
+			r := w0*float64(v0.ColorR) + w1*float64(v1.ColorR) + w2*float64(v2.ColorR)
+			g := w0*float64(v0.ColorG) + w1*float64(v1.ColorG) + w2*float64(v2.ColorG)
+			b := w0*float64(v0.ColorB) + w1*float64(v1.ColorB) + w2*float64(v2.ColorB)
+			a := w0*float64(v0.ColorA) + w1*float64(v1.ColorA) + w2*float64(v2.ColorA)
+
+			if tex != nil {
+				sx := w0*float64(v0.SrcX) + w1*float64(v1.SrcX) + w2*float64(v2.SrcX)
+				sy := w0*float64(v0.SrcY) + w1*float64(v1.SrcY) + w2*float64(v2.SrcY)
+				tr, tg, tb, ta := sampleTexture(tex, sx, sy)
+				r, g, b, a = r*tr, g*tg, b*tb, a*ta
+			}
+
+			off := dst.PixOffset(px, py)
+			pix := dst.Pix[off : off+4 : off+4]
+			pix[0] = max(pix[0], unitToByte(r))
+			pix[1] = max(pix[1], unitToByte(g))
+			pix[2] = max(pix[2], unitToByte(b))
+			pix[3] = max(pix[3], unitToByte(a))
+		}
+	}
+}
+
+// unitToByte 把 [0, 1] 的分量转换为 0-255，超出范围的值会被截断
+func unitToByte(v float64) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 1 {
+		return 255
+	}
+	return uint8(v*255 + 0.5)
+}
+
+// sampleTexture 返回 tex 在 (x, y) 处的预乘颜色 (0-1)，横向重复、纵向截断
+func sampleTexture(tex *image.RGBA, x, y float64) (r, g, b, a float64) {
+	tb := tex.Bounds()
+	w, h := tb.Dx(), tb.Dy()
+	px := int(math.Floor(x)) % w
+	if px < 0 {
+		px += w
+	}
+	py := min(max(int(math.Floor(y)), 0), h-1)
+	c := tex.RGBAAt(tb.Min.X+px, tb.Min.Y+py)
+	return float64(c.R) / 255, float64(c.G) / 255, float64(c.B) / 255, float64(c.A) / 255
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"flag"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "重新生成 testdata 中的参考图片")
+
+const (
+	// 参考图片比较的容差：每个通道允许的差值，以及允许超出该差值的像素数
+	// 不同架构的浮点运算 (如 FMA) 可能让三角形边缘的个别像素落在另一侧
+	goldenChannelTolerance = 3
+	goldenPixelTolerance   = 8
+)
+
+// goldenScenes 参考图片覆盖的场景，图片保存在 testdata/<name>.png
+var goldenScenes = []struct {
+	name   string
+	setup  func(cfg *Config)
+	script func(in *ScriptedInput)
+}{
+	{
+		name:  "tail",
+		setup: func(cfg *Config) {},
+		script: func(in *ScriptedInput) {
+			in.Append(InputState{X: 20, Y: 100})
+			in.MoveTo(70, 30, 6)
+			in.MoveTo(140, 90, 6)
+		},
+	},
+	{
+		// 恒定宽度的宽轨迹的圆头、转角处的圆形接头
+		name: "caps",
+		setup: func(cfg *Config) {
+			cfg.TailWidth = 24
+			cfg.TailLength = 40
+			cfg.WidthProfile = Profile{Shape: ProfileConstant}
+			cfg.AlphaProfile = Profile{Shape: ProfileConstant}
+		},
+		script: func(in *ScriptedInput) {
+			in.Append(InputState{X: 30, Y: 30})
+			in.MoveTo(130, 30, 4)
+			in.MoveTo(80, 95, 4)
+		},
+	},
+	{
+		// 左键单击、右键双击
+		name:  "ripples",
+		setup: func(cfg *Config) {},
+		script: func(in *ScriptedInput) {
+			in.Append(InputState{X: 50, Y: 60})
+			in.Click(MouseButtonLeft)
+			in.Wait(4)
+			in.Append(InputState{X: 115, Y: 60})
+			in.Click(MouseButtonRight)
+			in.Click(MouseButtonRight)
+			in.Wait(6)
+		},
+	},
+	{
+		name: "rainbow",
+		setup: func(cfg *Config) {
+			cfg.IsRainbow = true
+			cfg.RainbowMode = RainbowTrail
+			cfg.RainbowSpread = 1
+			cfg.TailWidth = 14
+			cfg.TailLength = 30
+		},
+		script: func(in *ScriptedInput) {
+			in.Append(InputState{X: 15, Y: 60})
+			in.MoveTo(55, 20, 5)
+			in.MoveTo(100, 100, 5)
+			in.MoveTo(145, 25, 5)
+		},
+	},
+}
+
+// renderScene 回放场景脚本，返回最后一帧的软件渲染结果
+func renderScene(setup func(cfg *Config), script func(in *ScriptedInput)) *image.RGBA {
+	cfg := DefaultConfig()
+	setup(cfg)
+	in := NewScriptedInput()
+	script(in)
+	g := newScriptedGame(cfg, in)
+	runScript(g, in)
+
+	dst := image.NewRGBA(image.Rect(0, 0, 160, 120))
+	g.traceManager.DrawRGBA(dst)
+	return dst
+}
+
+func TestGoldenImages(t *testing.T) {
+	for _, sc := range goldenScenes {
+		t.Run(sc.name, func(t *testing.T) {
+			got := renderScene(sc.setup, sc.script)
+			path := filepath.Join("testdata", sc.name+".png")
+			if *updateGolden {
+				if err := writePNG(path, got); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := readPNG(path)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if bad := diffImages(got, want); bad > goldenPixelTolerance {
+				out := filepath.Join(t.TempDir(), sc.name+".png")
+				if err := writePNG(out, got); err != nil {
+					t.Fatal(err)
+				}
+				t.Errorf("%d pixels differ from %s, got image saved to %s", bad, path, out)
+			}
+		})
+	}
+}
+
+// diffImages 返回任一通道差值超过容差的像素数，尺寸不同时返回所有像素数
+func diffImages(a, b *image.RGBA) int {
+	if a.Bounds() != b.Bounds() {
+		return a.Bounds().Dx() * a.Bounds().Dy()
+	}
+	bad := 0
+	for i := 0; i < len(a.Pix); i += 4 {
+		for c := 0; c < 4; c++ {
+			d := int(a.Pix[i+c]) - int(b.Pix[i+c])
+			if d > goldenChannelTolerance || d < -goldenChannelTolerance {
+				bad++
+				break
+			}
+		}
+	}
+	return bad
+}
+
+func readPNG(path string) (*image.RGBA, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba, nil
+	}
+	// PNG 保存的是非预乘颜色，解码结果转换回预乘的 RGBA
+	rgba := image.NewRGBA(img.Bounds())
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			rgba.Set(x, y, img.At(x, y))
+		}
+	}
+	return rgba, nil
+}
+
+func writePNG(path string, img *image.RGBA) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"math"
+)
+
+// 波纹形状
+const (
+	RippleCircle   = "circle"
+	RippleSquare   = "square"
+	RippleDiamond  = "diamond"
+	RippleTriangle = "triangle"
+)
+
+// RippleStyle 单个鼠标按键的波纹样式
+// 数值为 0、颜色 alpha 为 0 时沿用全局波纹设置和轨迹颜色
+type RippleStyle struct {
+	Enabled  bool     `json:"enabled"`
+	Color    [4]uint8 `json:"color"`    // RGBA，alpha 为 0 表示使用轨迹颜色
+	Shape    string   `json:"shape"`    // "circle", "square", "diamond", "triangle"
+	Width    float64  `json:"width"`    // 圆环宽度，0 = ripple_width
+	Growth   float64  `json:"growth"`   // 扩散速度 (像素/秒)，0 = ripple_growth
+	Duration float64  `json:"duration"` // 持续时间 (秒)，0 = ripple_duration
+}
+
+// ButtonRipples 各鼠标按键的波纹样式
+type ButtonRipples struct {
+	Left   RippleStyle `json:"left"`
+	Right  RippleStyle `json:"right"`
+	Middle RippleStyle `json:"middle"`
+	X1     RippleStyle `json:"x1"`
+	X2     RippleStyle `json:"x2"`
+}
+
+// defaultButtonRipples 默认样式：左键沿用轨迹颜色的圆形，其他按键用不同颜色和形状区分
+func defaultButtonRipples() ButtonRipples {
+	return ButtonRipples{
+		Left:   RippleStyle{Enabled: true, Shape: RippleCircle},
+		Right:  RippleStyle{Enabled: true, Color: [4]uint8{0, 160, 255, 255}, Shape: RippleSquare},
+		Middle: RippleStyle{Enabled: true, Color: [4]uint8{0, 210, 120, 255}, Shape: RippleDiamond},
+		X1:     RippleStyle{Enabled: true, Color: [4]uint8{255, 200, 0, 255}, Shape: RippleTriangle},
+		X2:     RippleStyle{Enabled: true, Color: [4]uint8{255, 0, 200, 255}, Shape: RippleTriangle},
+	}
+}
+
+// For 返回指定按键的样式
+func (b *ButtonRipples) For(btn MouseButton) *RippleStyle {
+	switch btn {
+	case MouseButtonRight:
+		return &b.Right
+	case MouseButtonMiddle:
+		return &b.Middle
+	case MouseButtonX1:
+		return &b.X1
+	case MouseButtonX2:
+		return &b.X2
+	default:
+		return &b.Left
+	}
+}
+
+// normalize 补全缺省形状
+func (b *ButtonRipples) normalize() {
+	for btn := MouseButton(0); btn < mouseButtonCount; btn++ {
+		if s := b.For(btn); s.Shape == "" {
+			s.Shape = RippleCircle
+		}
+	}
+}
+
+// ModifierStyle 按住修饰键点击时的波纹样式
+// 颜色的 alpha 为 0 表示该修饰键不改变波纹颜色
+type ModifierStyle struct {
+	Enabled   bool     `json:"enabled"`
+	ShowLabel bool     `json:"show_label"` // 在波纹旁显示 "Ctrl+Shift" 标签
+	LabelSize float64  `json:"label_size"` // 标签字号 (像素)
+	Ctrl      [4]uint8 `json:"ctrl"`
+	Alt       [4]uint8 `json:"alt"`
+	Shift     [4]uint8 `json:"shift"`
+	Super     [4]uint8 `json:"super"`
+}
+
+func defaultModifierStyle() ModifierStyle {
+	return ModifierStyle{
+		Enabled:   true,
+		ShowLabel: true,
+		LabelSize: 13,
+		Ctrl:      [4]uint8{255, 150, 0, 255},
+		Alt:       [4]uint8{190, 90, 255, 255},
+		Shift:     [4]uint8{0, 200, 255, 255},
+		Super:     [4]uint8{255, 70, 70, 255},
+	}
+}
+
+// Color 返回 mods 对应的波纹颜色，按 Ctrl、Alt、Shift、Super 的顺序取第一个设置了颜色的修饰键
+func (m *ModifierStyle) Color(mods Modifier) ([4]uint8, bool) {
+	for _, c := range []struct {
+		mod   Modifier
+		color [4]uint8
+	}{
+		{ModCtrl, m.Ctrl},
+		{ModAlt, m.Alt},
+		{ModShift, m.Shift},
+		{ModSuper, m.Super},
+	} {
+		if mods&c.mod != 0 && c.color[3] != 0 {
+			return c.color, true
+		}
+	}
+	return [4]uint8{}, false
+}
+
+// ringPolygon 返回形状对应的边数和起始角度
+func ringPolygon(shape string) (sides int, phase float64) {
+	switch shape {
+	case RippleSquare:
+		return 4, math.Pi / 4
+	case RippleDiamond:
+		return 4, 0
+	case RippleTriangle:
+		return 3, -math.Pi / 2
+	default:
+		return 20, 0 // 降低分段数以优化性能
+	}
+}
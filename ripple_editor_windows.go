@@ -0,0 +1,194 @@
+package main
+
+import (
+	"github.com/lxn/walk"
+	. "github.com/lxn/walk/declarative"
+	"github.com/lxn/win"
+)
+
+// rippleEditor 配置窗口中的按键波纹编辑器
+// 直接修改 cfg.ButtonRipples，每次修改后调用 onChange
+type rippleEditor struct {
+	cfg      *Config
+	owner    **walk.MainWindow
+	onChange func()
+
+	button   *walk.ComboBox
+	enabled  *walk.CheckBox
+	shape    *walk.ComboBox
+	useTrail *walk.CheckBox
+	width    *walk.NumberEdit
+	growth   *walk.NumberEdit
+	duration *walk.NumberEdit
+
+	// 切换按键时正在回填控件，忽略控件的变更事件
+	loading bool
+
+	customColors [16]win.COLORREF
+}
+
+func newRippleEditor(cfg *Config, owner **walk.MainWindow, onChange func()) *rippleEditor {
+	return &rippleEditor{cfg: cfg, owner: owner, onChange: onChange}
+}
+
+// Widget 返回编辑器的声明式控件
+func (e *rippleEditor) Widget() Widget {
+	buttons := []string{T("ButtonLeft"), T("ButtonRight"), T("ButtonMiddle"), T("ButtonX1"), T("ButtonX2")}
+	shapes := []string{T("ShapeCircle"), T("ShapeSquare"), T("ShapeDiamond"), T("ShapeTriangle")}
+
+	return GroupBox{
+		Title:  T("ButtonRipples"),
+		Layout: Grid{Columns: 2},
+		Children: []Widget{
+			Label{Text: T("MouseButton")},
+			ComboBox{
+				AssignTo:              &e.button,
+				Model:                 buttons,
+				CurrentIndex:          0,
+				OnCurrentIndexChanged: e.load,
+			},
+			CheckBox{
+				AssignTo:         &e.enabled,
+				Text:             T("RippleEnabled"),
+				OnCheckedChanged: e.store,
+				ColumnSpan:       2,
+			},
+			Label{Text: T("RippleShape")},
+			ComboBox{
+				AssignTo:              &e.shape,
+				Model:                 shapes,
+				OnCurrentIndexChanged: e.store,
+			},
+			CheckBox{
+				AssignTo:         &e.useTrail,
+				Text:             T("RippleUseTrail"),
+				OnCheckedChanged: e.store,
+			},
+			PushButton{Text: T("StopColor"), OnClicked: e.pickColor},
+			Label{Text: T("RippleWidth")},
+			NumberEdit{AssignTo: &e.width, Decimals: 1, OnValueChanged: e.store},
+			Label{Text: T("RippleGrowth")},
+			NumberEdit{AssignTo: &e.growth, Decimals: 0, OnValueChanged: e.store},
+			Label{Text: T("RippleDuration")},
+			NumberEdit{AssignTo: &e.duration, Decimals: 2, OnValueChanged: e.store},
+			Label{Text: T("RippleZeroHint"), ColumnSpan: 2},
+		},
+	}
+}
+
+// rippleShapes 与形状下拉框的顺序对应
+var rippleShapes = []string{RippleCircle, RippleSquare, RippleDiamond, RippleTriangle}
+
+func (e *rippleEditor) style() *RippleStyle {
+	return e.cfg.ButtonRipples.For(MouseButton(max(e.button.CurrentIndex(), 0)))
+}
+
+// load 把当前按键的样式填入控件
+func (e *rippleEditor) load() {
+	if e.enabled == nil {
+		return // 控件尚未全部创建
+	}
+	s := e.style()
+	e.loading = true
+	defer func() { e.loading = false }()
+
+	e.enabled.SetChecked(s.Enabled)
+	e.shape.SetCurrentIndex(0)
+	for i, shape := range rippleShapes {
+		if shape == s.Shape {
+			e.shape.SetCurrentIndex(i)
+		}
+	}
+	e.useTrail.SetChecked(s.Color[3] == 0)
+	e.width.SetValue(s.Width)
+	e.growth.SetValue(s.Growth)
+	e.duration.SetValue(s.Duration)
+}
+
+// store 把控件的值写回当前按键的样式
+func (e *rippleEditor) store() {
+	if e.loading || e.duration == nil {
+		return
+	}
+	s := e.style()
+	s.Enabled = e.enabled.Checked()
+	if idx := e.shape.CurrentIndex(); idx >= 0 && idx < len(rippleShapes) {
+		s.Shape = rippleShapes[idx]
+	}
+	if e.useTrail.Checked() {
+		s.Color[3] = 0
+	} else if s.Color[3] == 0 {
+		s.Color[3] = 255
+	}
+	s.Width = e.width.Value()
+	s.Growth = e.growth.Value()
+	s.Duration = e.duration.Value()
+
+	if e.onChange != nil {
+		e.onChange()
+	}
+}
+
+// pickColor 选择当前按键的波纹颜色，选择后不再使用轨迹颜色
+func (e *rippleEditor) pickColor() {
+	s := e.style()
+	if !chooseColor(*e.owner, &e.customColors, &s.Color) {
+		return
+	}
+	s.Color[3] = 255
+	e.load()
+	if e.onChange != nil {
+		e.onChange()
+	}
+}
+
+// ModifierWidget 返回修饰键波纹设置的声明式控件
+func (e *rippleEditor) ModifierWidget() Widget {
+	m := &e.cfg.ModifierRipples
+	var enabled, showLabel *walk.CheckBox
+
+	// pickModifier 返回修改指定修饰键颜色的回调
+	pickModifier := func(c *[4]uint8) func() {
+		return func() {
+			if !chooseColor(*e.owner, &e.customColors, c) {
+				return
+			}
+			// 之前未设置颜色时，选择后才启用
+			if c[3] == 0 {
+				c[3] = 255
+			}
+			e.onChange()
+		}
+	}
+
+	return GroupBox{
+		Title:  T("ModifierRipples"),
+		Layout: Grid{Columns: 4},
+		Children: []Widget{
+			CheckBox{
+				AssignTo: &enabled,
+				Text:     T("ModifierEnabled"),
+				Checked:  m.Enabled,
+				OnCheckedChanged: func() {
+					m.Enabled = enabled.Checked()
+					e.onChange()
+				},
+				ColumnSpan: 4,
+			},
+			CheckBox{
+				AssignTo: &showLabel,
+				Text:     T("ModifierLabel"),
+				Checked:  m.ShowLabel,
+				OnCheckedChanged: func() {
+					m.ShowLabel = showLabel.Checked()
+					e.onChange()
+				},
+				ColumnSpan: 4,
+			},
+			PushButton{Text: "Ctrl...", OnClicked: pickModifier(&m.Ctrl)},
+			PushButton{Text: "Alt...", OnClicked: pickModifier(&m.Alt)},
+			PushButton{Text: "Shift...", OnClicked: pickModifier(&m.Shift)},
+			PushButton{Text: superKeyName + "...", OnClicked: pickModifier(&m.Super)},
+		},
+	}
+}
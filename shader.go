@@ -0,0 +1,171 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 内置的着色器，Dir 中没有同名文件时使用
+//
+//go:embed shaders/*.kage
+var builtinShaders embed.FS
+
+const (
+	shaderExt = ".kage"
+	// 检查着色器文件是否被修改的间隔
+	shaderCheckInterval = time.Second
+)
+
+// ShaderConfig Kage 着色器设置
+// 着色器从 Dir 目录下的 <名称>.kage 加载，文件修改后自动重新编译
+//
+// 可用的 uniform：Time (秒)、Speed (光标速度，像素/秒)、Color (轨迹颜色 RGBA，未预乘)
+// 每个顶点的 custom 参数：x = 生命值 (1 -> 0)，y = 采样时的速度 (像素/秒)，
+// z = 沿路径的位置 (轨迹：0 = 头部，1 = 尾端；波纹：角度比例)，w = 横向位置 (-1 ~ 1，0 为中心线)
+type ShaderConfig struct {
+	Dir    string `json:"dir"`    // 着色器目录
+	Trail  string `json:"trail"`  // 轨迹着色器名称，为空时使用纯色
+	Ripple string `json:"ripple"` // 波纹着色器名称，为空时使用纯色
+}
+
+func defaultShaderConfig() ShaderConfig {
+	return ShaderConfig{Dir: "shaders"}
+}
+
+// shaderSlot 一个按名称加载的着色器
+type shaderSlot struct {
+	dir, name string
+	modTime   time.Time // 已加载文件的修改时间，内置着色器为零值
+	checked   time.Time // 上一次检查文件的时间
+	shader    *ebiten.Shader
+}
+
+// get 返回名称为 name 的着色器，加载或编译失败时返回 nil (使用纯色绘制)
+func (s *shaderSlot) get(dir, name string) *ebiten.Shader {
+	if name == "" {
+		s.release()
+		return nil
+	}
+
+	now := time.Now()
+	changed := s.dir != dir || s.name != name
+	if !changed && now.Sub(s.checked) < shaderCheckInterval {
+		return s.shader
+	}
+	s.checked = now
+
+	path := filepath.Join(dir, name+shaderExt)
+	readBuiltin := func() ([]byte, error) {
+		return builtinShaders.ReadFile("shaders/" + name + shaderExt)
+	}
+	info, err := os.Stat(path)
+	if err == nil {
+		if changed || !info.ModTime().Equal(s.modTime) {
+			// 文件无法使用时改用内置的同名着色器 (如果有)，文件再次修改后重新加载
+			if !s.load(dir, name, info.ModTime(), func() ([]byte, error) { return os.ReadFile(path) }) {
+				s.load(dir, name, info.ModTime(), readBuiltin)
+			}
+		}
+		return s.shader
+	}
+	// 目录中没有该文件时使用内置的同名着色器
+	if changed || !s.modTime.IsZero() {
+		s.load(dir, name, time.Time{}, readBuiltin)
+	}
+	return s.shader
+}
+
+// load 读取并编译着色器，返回是否成功
+func (s *shaderSlot) load(dir, name string, modTime time.Time, read func() ([]byte, error)) bool {
+	s.release()
+	s.dir, s.name, s.modTime = dir, name, modTime
+
+	src, err := read()
+	if err != nil {
+		log.Printf("Failed to read shader %s: %v", name, err)
+		return false
+	}
+	shader, err := ebiten.NewShader(src)
+	if err != nil {
+		log.Printf("Failed to compile shader %s: %v", name, err)
+		return false
+	}
+	if err := checkShaderUniforms(shader); err != nil {
+		log.Printf("Shader %s declares incompatible uniforms: %v", name, err)
+		shader.Deallocate()
+		return false
+	}
+	s.shader = shader
+	return true
+}
+
+// shaderUniforms 返回传给着色器的 uniform，color 为未预乘的轨迹颜色
+func shaderUniforms(t, speed float32, color [4]float32) map[string]any {
+	return map[string]any{
+		"Time":  t,
+		"Speed": speed,
+		"Color": color[:],
+	}
+}
+
+// checkShaderUniforms 用与绘制时相同类型的 uniform 试画一次
+// 着色器用其他类型声明 Time、Speed、Color 时 DrawTrianglesShader32 会 panic，这里转换为错误
+func checkShaderUniforms(shader *ebiten.Shader) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	img := ebiten.NewImage(1, 1)
+	defer img.Deallocate()
+	vertices := []ebiten.Vertex{{}, {DstX: 1}, {DstY: 1}}
+	img.DrawTrianglesShader32(vertices, []uint32{0, 1, 2}, shader, &ebiten.DrawTrianglesShaderOptions{
+		Uniforms: shaderUniforms(0, 0, [4]float32{}),
+	})
+	return nil
+}
+
+func (s *shaderSlot) release() {
+	if s.shader != nil {
+		s.shader.Deallocate()
+		s.shader = nil
+	}
+	s.dir, s.name = "", ""
+}
+
+// availableShaders 返回内置着色器和 dir 目录下着色器的名称，已排序去重
+func availableShaders(dir string) []string {
+	var names []string
+	add := func(entries []fs.DirEntry) {
+		for _, e := range entries {
+			if name, ok := strings.CutSuffix(e.Name(), shaderExt); ok && !e.IsDir() {
+				names = append(names, name)
+			}
+		}
+	}
+	if entries, err := builtinShaders.ReadDir("shaders"); err == nil {
+		add(entries)
+	}
+	if entries, err := os.ReadDir(dir); err == nil {
+		add(entries)
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// shaderSet 轨迹和波纹使用的着色器
+type shaderSet struct {
+	trail, ripple shaderSlot
+
+	// 不使用着色器的部分合并后的索引，每帧复用
+	flat []uint32
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// 定位环的脉动频率 (次/秒)
+	shakePulseRate = 3.0
+	// 脉动幅度 (相对半径)
+	shakePulseAmount = 0.15
+)
+
+// ShakeConfig 晃动找光标设置
+// 在短时间内来回晃动鼠标时，在光标处显示放大的脉动定位环
+type ShakeConfig struct {
+	Enabled     bool     `json:"enabled"`
+	Style       string   `json:"style"`        // "ring", "fill"，与光环样式相同
+	Reversals   int      `json:"reversals"`    // 时间窗口内需要的方向反转次数，越少越灵敏
+	Window      float64  `json:"window"`       // 时间窗口 (秒)
+	MinDistance float64  `json:"min_distance"` // 每次摆动的最小距离 (像素)
+	Duration    float64  `json:"duration"`     // 定位环持续时间 (秒)
+	Radius      float64  `json:"radius"`       // 定位环半径 (像素)
+	Width       float64  `json:"width"`        // 定位环宽度 (像素)
+	Color       [4]uint8 `json:"color"`        // RGBA，alpha 为 0 表示使用轨迹颜色
+}
+
+func defaultShakeConfig() ShakeConfig {
+	return ShakeConfig{
+		Enabled:     false,
+		Style:       HaloRing,
+		Reversals:   4,
+		Window:      0.8,
+		MinDistance: 40,
+		Duration:    1.2,
+		Radius:      90,
+		Width:       8,
+	}
+}
+
+// shakeAxis 单个坐标轴上的摆动状态
+type shakeAxis struct {
+	dir       int     // 当前摆动方向：-1、0 (尚未开始)、1
+	origin    float64 // dir 为 0 时的起点
+	extreme   float64 // 当前摆动到达的最远位置
+	reversals []time.Time
+}
+
+// add 记录坐标 v，从最远位置往回移动超过 minDist 时算一次反转，返回窗口内的反转次数
+func (a *shakeAxis) add(v float64, t time.Time, minDist, window float64) int {
+	switch {
+	case a.dir == 0:
+		if math.Abs(v-a.origin) >= minDist {
+			a.dir = int(math.Copysign(1, v-a.origin))
+			a.extreme = v
+		}
+	case float64(a.dir)*(v-a.extreme) > 0:
+		a.extreme = v
+	case math.Abs(v-a.extreme) >= minDist:
+		a.dir = -a.dir
+		a.extreme = v
+		a.reversals = append(a.reversals, t)
+	}
+
+	// 移除窗口之外的反转
+	n := 0
+	for _, r := range a.reversals {
+		if elapsedSeconds(r, t) <= window {
+			a.reversals[n] = r
+			n++
+		}
+	}
+	a.reversals = a.reversals[:n]
+	return n
+}
+
+// ShakeDetector 根据采样点检测来回晃动
+// 水平和垂直方向分别统计反转次数，任一方向达到阈值即判定为晃动
+type ShakeDetector struct {
+	x, y    shakeAxis
+	started bool
+}
+
+// Add 加入一个采样点，返回是否检测到晃动；检测到后重新开始统计
+func (d *ShakeDetector) Add(cfg *ShakeConfig, p TracePoint) bool {
+	if !d.started {
+		d.Reset()
+		d.x.origin, d.y.origin = p.X, p.Y
+		d.started = true
+		return false
+	}
+	nx := d.x.add(p.X, p.Time, cfg.MinDistance, cfg.Window)
+	ny := d.y.add(p.Y, p.Time, cfg.MinDistance, cfg.Window)
+	if max(nx, ny) >= max(cfg.Reversals, 1) {
+		d.Reset()
+		d.x.origin, d.y.origin = p.X, p.Y
+		d.started = true
+		return true
+	}
+	return false
+}
+
+// Reset 清除所有状态
+func (d *ShakeDetector) Reset() {
+	*d = ShakeDetector{}
+}
+
+// shakeLocator 晃动后显示的定位环，跟随光标
+type shakeLocator struct {
+	Life float64 // 1.0 -> 0.0
+	Age  float64 // 已显示的时间 (秒)，用于脉动
+}
+
+// updateShake 把新的采样点交给检测器并推进定位环动画，返回定位环是否可见
+func (tm *TraceManager) updateShake(sampled []TracePoint, dt float64) bool {
+	cfg := &tm.config.Shake
+	if !cfg.Enabled {
+		tm.locator.Life = 0
+		return false
+	}
+	for _, p := range sampled {
+		if !tm.shake.Add(cfg, p) {
+			continue
+		}
+		// 定位环仍在显示时继续晃动只延长显示时间，不重新播放出现动画
+		if tm.locator.Life > 0 {
+			tm.locator.Life = 1
+		} else {
+			tm.locator = shakeLocator{Life: 1}
+		}
+	}
+
+	if tm.locator.Life <= 0 {
+		return false
+	}
+	decay := 1.0
+	if cfg.Duration > 0 {
+		decay = dt / cfg.Duration
+	}
+	tm.locator.Life -= decay
+	tm.locator.Age += dt
+	return tm.locator.Life > 0
+}
+
+// buildShakeGeometry 在光标处生成脉动的定位环
+// 出现时从三倍半径快速收拢，之后按 shakePulseRate 脉动，最后淡出
+func (tm *TraceManager) buildShakeGeometry() {
+	cfg := &tm.config.Shake
+	l := tm.locator
+	if !cfg.Enabled || l.Life <= 0 {
+		return
+	}
+
+	intro := math.Max(0, 1-l.Age/0.2) // 出现动画的剩余比例
+	pulse := math.Sin(2 * math.Pi * shakePulseRate * l.Age)
+	radius := cfg.Radius * (1 + intro*2 + shakePulseAmount*pulse)
+	width := math.Max(1, cfg.Width)
+
+	fade := math.Min(1, l.Life*3)
+	x, y := tm.lastX, tm.lastY
+	if cfg.Style == HaloFill {
+		tm.addDisc(x, y, radius, haloSegments, tm.styleColor(cfg.Color, fade*0.3))
+	} else {
+		// 内侧的细环，增强对比
+		tm.addRing(x, y, radius*0.6, width/2, RippleCircle, tm.styleColor(cfg.Color, fade*0.5))
+	}
+	tm.addRing(x, y, radius, width, RippleCircle, tm.styleColor(cfg.Color, fade))
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// shakePath 生成 duration 秒内按 60 Hz 采样的轨迹：沿 (dx, dy) 方向在 0 和 amplitude 像素之间往返，
+// 每 halfPeriod 秒换一次方向
+func shakePath(dx, dy, amplitude, halfPeriod, duration float64) []TracePoint {
+	start := time.Unix(0, 0)
+	var pts []TracePoint
+	for i := 0; float64(i)/60 <= duration; i++ {
+		t := float64(i) / 60
+		k, frac := math.Modf(t / halfPeriod)
+		if int(k)%2 == 1 {
+			frac = 1 - frac
+		}
+		d := frac * amplitude
+		pts = append(pts, TracePoint{
+			X:    200 + dx*d,
+			Y:    200 + dy*d,
+			Time: start.Add(time.Duration(t * float64(time.Second))),
+		})
+	}
+	return pts
+}
+
+// shakeCount 把 pts 依次交给新的检测器，返回检测到晃动的次数
+func shakeCount(cfg *ShakeConfig, pts []TracePoint) int {
+	var d ShakeDetector
+	n := 0
+	for _, p := range pts {
+		if d.Add(cfg, p) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestShakeDetector(t *testing.T) {
+	tests := []struct {
+		name string
+		pts  []TracePoint
+		want int
+	}{
+		// 默认设置：0.8 秒内 4 次反转，每次摆动至少 40 像素
+		{"fast horizontal shake", shakePath(1, 0, 120, 0.1, 0.6), 1},
+		{"fast vertical shake", shakePath(0, 1, 120, 0.1, 0.6), 1},
+		{"fast diagonal shake", shakePath(0.7, 0.7, 120, 0.1, 0.6), 1},
+		{"long shake fires again", shakePath(1, 0, 120, 0.1, 1.2), 2},
+		{"straight move", shakePath(1, 0, 1500, 2, 1.5), 0},
+		{"diagonal move", shakePath(0.7, -0.7, 1500, 2, 1.5), 0},
+		{"slow wiggle", shakePath(1, 0, 120, 0.4, 3), 0},
+		{"small jitter", shakePath(1, 0, 30, 0.05, 1), 0},
+	}
+	cfg := defaultShakeConfig()
+	for _, tt := range tests {
+		if got := shakeCount(&cfg, tt.pts); got != tt.want {
+			t.Errorf("%s: detected %d shakes, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestShakeDetectorSensitivity(t *testing.T) {
+	// 降低反转次数和摆动距离后，较小较慢的晃动也能触发
+	pts := shakePath(1, 0, 30, 0.25, 1)
+	cfg := defaultShakeConfig()
+	if n := shakeCount(&cfg, pts); n != 0 {
+		t.Fatalf("default settings detected %d shakes, want 0", n)
+	}
+	cfg.Reversals = 2
+	cfg.MinDistance = 20
+	if n := shakeCount(&cfg, pts); n == 0 {
+		t.Error("sensitive settings detected no shake")
+	}
+}
+
+func TestShakeLocator(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Shake.Enabled = true
+	in := NewScriptedInput(InputState{X: 200, Y: 200})
+	for i := 0; i < 4; i++ {
+		in.MoveTo(320, 200, 6)
+		in.MoveTo(200, 200, 6)
+	}
+	g := newScriptedGame(cfg, in)
+	runScript(g, in)
+	if g.traceManager.locator.Life <= 0 {
+		t.Fatal("no locator ring after shaking")
+	}
+
+	// 定位环在 Duration 秒后消失
+	in.Wait(int(cfg.Shake.Duration*60) + 1)
+	runScript(g, in)
+	if g.traceManager.locator.Life > 0 {
+		t.Errorf("locator ring still visible after %vs", cfg.Shake.Duration)
+	}
+}
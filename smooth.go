@@ -0,0 +1,129 @@
+package main
+
+import (
+	"math"
+)
+
+// 轨迹平滑模式
+const (
+	SmoothingNone        = "none"        // 直接连接采样点
+	SmoothingCatmullRom  = "catmull_rom" // 均匀参数化 Catmull-Rom (基数样条)
+	SmoothingCentripetal = "centripetal" // 向心参数化 Catmull-Rom，急转弯处不会打结
+)
+
+const (
+	// 自适应细分：每段细分后子段的最大长度 (像素) 和最大转角 (弧度)
+	smoothMaxStep  = 6.0
+	smoothMaxAngle = math.Pi / 18
+	// 每段最多细分次数
+	smoothMaxSubdiv = 16
+	// 平滑后点数上限，限制长轨迹的细分开销
+	maxSmoothedPoints = 1024
+)
+
+// smoothTrail 在采样点之间插值出平滑曲线，结果追加到 dst[:0] 并返回
+// 细分次数根据段长 (移动速度) 和两端的转角 (曲率) 自适应决定，
+// 慢速平直的部分几乎不增加点数。Life 和 Speed 沿段线性插值
+func smoothTrail(dst, pts []TracePoint, mode string, tension float64) []TracePoint {
+	dst = dst[:0]
+	if len(pts) < 3 || (mode != SmoothingCatmullRom && mode != SmoothingCentripetal) {
+		return append(dst, pts...)
+	}
+	tension = math.Max(0, math.Min(1, tension))
+
+	// 为了控制总点数，先统计每段的细分数，超过上限时整体按比例缩减
+	total := 1
+	for i := 0; i < len(pts)-1; i++ {
+		total += segmentSubdiv(pts, i)
+	}
+	scale := 1.0
+	if total > maxSmoothedPoints {
+		scale = float64(maxSmoothedPoints) / float64(total)
+	}
+
+	for i := 0; i < len(pts)-1; i++ {
+		p0, p1, p2, p3 := neighbours(pts, i)
+		m1x, m1y, m2x, m2y := tangents(p0, p1, p2, p3, mode)
+		m1x *= 1 - tension
+		m1y *= 1 - tension
+		m2x *= 1 - tension
+		m2y *= 1 - tension
+
+		n := max(1, int(float64(segmentSubdiv(pts, i))*scale))
+		for k := 0; k < n; k++ {
+			t := float64(k) / float64(n)
+			t2 := t * t
+			t3 := t2 * t
+			// 三次 Hermite 基函数
+			h00 := 2*t3 - 3*t2 + 1
+			h10 := t3 - 2*t2 + t
+			h01 := -2*t3 + 3*t2
+			h11 := t3 - t2
+			dst = append(dst, TracePoint{
+				X:     h00*p1.X + h10*m1x + h01*p2.X + h11*m2x,
+				Y:     h00*p1.Y + h10*m1y + h01*p2.Y + h11*m2y,
+				Life:  p1.Life + (p2.Life-p1.Life)*t,
+				Time:  p1.Time,
+				Speed: p1.Speed + (p2.Speed-p1.Speed)*t,
+			})
+		}
+	}
+	return append(dst, pts[len(pts)-1])
+}
+
+// segmentSubdiv 计算第 i 段 (pts[i] -> pts[i+1]) 的细分数
+func segmentSubdiv(pts []TracePoint, i int) int {
+	p0, p1, p2, p3 := neighbours(pts, i)
+	length := math.Hypot(p2.X-p1.X, p2.Y-p1.Y)
+
+	// 段两端的转角，取较大者作为曲率估计
+	turn := math.Max(turnAngle(p0, p1, p2), turnAngle(p1, p2, p3))
+
+	n := int(math.Ceil(math.Max(length/smoothMaxStep, turn/smoothMaxAngle)))
+	return max(1, min(n, smoothMaxSubdiv))
+}
+
+// neighbours 返回第 i 段的四个控制点，首尾缺失的控制点用镜像外推
+func neighbours(pts []TracePoint, i int) (p0, p1, p2, p3 TracePoint) {
+	p1, p2 = pts[i], pts[i+1]
+	if i > 0 {
+		p0 = pts[i-1]
+	} else {
+		p0 = TracePoint{X: 2*p1.X - p2.X, Y: 2*p1.Y - p2.Y}
+	}
+	if i+2 < len(pts) {
+		p3 = pts[i+2]
+	} else {
+		p3 = TracePoint{X: 2*p2.X - p1.X, Y: 2*p2.Y - p1.Y}
+	}
+	return
+}
+
+// tangents 计算 p1、p2 处的切线 (已换算到 [0, 1] 参数区间)
+func tangents(p0, p1, p2, p3 TracePoint, mode string) (m1x, m1y, m2x, m2y float64) {
+	if mode == SmoothingCatmullRom {
+		return (p2.X - p0.X) / 2, (p2.Y - p0.Y) / 2, (p3.X - p1.X) / 2, (p3.Y - p1.Y) / 2
+	}
+
+	// 向心参数化：节点间隔取距离的平方根
+	const eps = 1e-4
+	d01 := math.Max(math.Sqrt(math.Hypot(p1.X-p0.X, p1.Y-p0.Y)), eps)
+	d12 := math.Max(math.Sqrt(math.Hypot(p2.X-p1.X, p2.Y-p1.Y)), eps)
+	d23 := math.Max(math.Sqrt(math.Hypot(p3.X-p2.X, p3.Y-p2.Y)), eps)
+
+	m1x = d12 * ((p1.X-p0.X)/d01 - (p2.X-p0.X)/(d01+d12) + (p2.X-p1.X)/d12)
+	m1y = d12 * ((p1.Y-p0.Y)/d01 - (p2.Y-p0.Y)/(d01+d12) + (p2.Y-p1.Y)/d12)
+	m2x = d12 * ((p2.X-p1.X)/d12 - (p3.X-p1.X)/(d12+d23) + (p3.X-p2.X)/d23)
+	m2y = d12 * ((p2.Y-p1.Y)/d12 - (p3.Y-p1.Y)/(d12+d23) + (p3.Y-p2.Y)/d23)
+	return
+}
+
+// turnAngle 返回 a->b 与 b->c 两个方向之间的夹角 (0 - π)
+func turnAngle(a, b, c TracePoint) float64 {
+	x1, y1 := b.X-a.X, b.Y-a.Y
+	x2, y2 := c.X-b.X, c.Y-b.Y
+	if (x1 == 0 && y1 == 0) || (x2 == 0 && y2 == 0) {
+		return 0
+	}
+	return math.Abs(math.Atan2(x1*y2-y1*x2, x1*x2+y1*y2))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// zigzag 返回 n 个在 (0, 0) 和 (step, step) 之间来回的采样点，生命从 1 递减
+func zigzag(n int, step float64) []TracePoint {
+	pts := make([]TracePoint, n)
+	for i := range pts {
+		pts[i] = TracePoint{X: float64(i) * step, Y: float64(i%2) * step, Life: 1 - float64(i)/float64(n)}
+	}
+	return pts
+}
+
+func TestSmoothTrail(t *testing.T) {
+	tests := []struct {
+		name string
+		pts  []TracePoint
+	}{
+		{"straight", []TracePoint{{X: 0, Y: 0, Life: 1}, {X: 10, Y: 0, Life: 0.8}, {X: 20, Y: 0, Life: 0.6}, {X: 30, Y: 0, Life: 0.4}}},
+		{"sharp corner", []TracePoint{{X: 0, Y: 0, Life: 1}, {X: 50, Y: 0, Life: 0.9}, {X: 0, Y: 1, Life: 0.8}}},
+		{"repeated point", []TracePoint{{X: 5, Y: 5, Life: 1}, {X: 5, Y: 5, Life: 0.9}, {X: 40, Y: 20, Life: 0.8}, {X: 60, Y: 0, Life: 0.7}}},
+		{"zigzag", zigzag(20, 15)},
+	}
+	for _, mode := range []string{SmoothingCatmullRom, SmoothingCentripetal} {
+		for _, tt := range tests {
+			t.Run(mode+"/"+tt.name, func(t *testing.T) {
+				got := smoothTrail(nil, tt.pts, mode, 0)
+				if len(got) < len(tt.pts) {
+					t.Fatalf("%d points, want at least %d", len(got), len(tt.pts))
+				}
+				// 平滑曲线保留首尾端点
+				if got[0] != tt.pts[0] || got[len(got)-1] != tt.pts[len(tt.pts)-1] {
+					t.Errorf("endpoints = %+v, %+v, want %+v, %+v", got[0], got[len(got)-1], tt.pts[0], tt.pts[len(tt.pts)-1])
+				}
+				// 曲线经过每个采样点
+				j := 0
+				for _, p := range tt.pts {
+					for j < len(got) && (got[j].X != p.X || got[j].Y != p.Y) {
+						j++
+					}
+					if j == len(got) {
+						t.Fatalf("curve does not pass through (%v, %v)", p.X, p.Y)
+					}
+				}
+				// 生命沿轨迹单调不增，范围不超出采样点
+				for i := 1; i < len(got); i++ {
+					if got[i].Life > got[i-1].Life+1e-12 {
+						t.Fatalf("life increases at %d: %v -> %v", i, got[i-1].Life, got[i].Life)
+					}
+				}
+				for _, p := range got {
+					if math.IsNaN(p.X) || math.IsNaN(p.Y) {
+						t.Fatalf("NaN point %+v", p)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSmoothTrailPassthrough(t *testing.T) {
+	pts := zigzag(5, 20)
+	tests := []struct {
+		name string
+		pts  []TracePoint
+		mode string
+	}{
+		{"none", pts, SmoothingNone},
+		{"unknown mode", pts, "bezier"},
+		{"two points", pts[:2], SmoothingCatmullRom},
+	}
+	for _, tt := range tests {
+		got := smoothTrail(nil, tt.pts, tt.mode, 0)
+		if len(got) != len(tt.pts) {
+			t.Errorf("%s: %d points, want %d", tt.name, len(got), len(tt.pts))
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.pts[i] {
+				t.Errorf("%s: point %d = %+v, want %+v", tt.name, i, got[i], tt.pts[i])
+			}
+		}
+	}
+}
+
+func TestSmoothTrailTension(t *testing.T) {
+	// 张力为 1 时切线为零，直线段上的细分点仍在线段上
+	pts := []TracePoint{{X: 0, Y: 0}, {X: 30, Y: 0}, {X: 30, Y: 30}}
+	for _, p := range smoothTrail(nil, pts, SmoothingCatmullRom, 1) {
+		if p.Y != 0 && p.X != 30 {
+			t.Errorf("point (%v, %v) is off the polyline with tension 1", p.X, p.Y)
+		}
+	}
+}
+
+func TestSmoothTrailLimit(t *testing.T) {
+	// 长而曲折的轨迹，细分后的总点数不超过上限
+	pts := zigzag(500, 40)
+	got := smoothTrail(nil, pts, SmoothingCentripetal, 0)
+	if len(got) > maxSmoothedPoints {
+		t.Errorf("%d points, want at most %d", len(got), maxSmoothedPoints)
+	}
+	if got[0] != pts[0] || got[len(got)-1] != pts[len(pts)-1] {
+		t.Error("endpoints not preserved when the point count is limited")
+	}
+}
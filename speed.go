@@ -0,0 +1,78 @@
+package main
+
+import (
+	"math"
+)
+
+// SpeedStyle 速度到轨迹样式的映射
+// 每个数组为 [慢速时的值, 快速时的值]，速度在 MinSpeed 和 MaxSpeed 之间线性插值
+type SpeedStyle struct {
+	Enabled  bool    `json:"enabled"`
+	MinSpeed float64 `json:"min_speed"` // 低于该速度 (像素/秒) 按慢速处理
+	MaxSpeed float64 `json:"max_speed"` // 高于该速度按快速处理
+
+	Width    [2]float64 `json:"width"`    // 宽度系数
+	Alpha    [2]float64 `json:"alpha"`    // 透明度系数
+	Brighten [2]float64 `json:"brighten"` // 向白色混合的比例 (0 = 原色，1 = 白色)
+	Spacing  [2]float64 `json:"spacing"`  // 采样新点的最小间距 (像素)，越小轨迹点越密
+}
+
+// defaultSpeedStyle 默认映射：越快越细越亮
+func defaultSpeedStyle() SpeedStyle {
+	return SpeedStyle{
+		Enabled:  false,
+		MinSpeed: 200,
+		MaxSpeed: 3000,
+		Width:    [2]float64{1, 0.6},
+		Alpha:    [2]float64{0.8, 1},
+		Brighten: [2]float64{0, 0.5},
+		Spacing:  [2]float64{2, 2},
+	}
+}
+
+// amount 把速度归一化到 0 (慢) - 1 (快)
+func (s *SpeedStyle) amount(speed float64) float64 {
+	if s.MaxSpeed <= s.MinSpeed {
+		if speed >= s.MaxSpeed {
+			return 1
+		}
+		return 0
+	}
+	return math.Max(0, math.Min(1, (speed-s.MinSpeed)/(s.MaxSpeed-s.MinSpeed)))
+}
+
+func (s *SpeedStyle) lerp(v [2]float64, speed float64) float64 {
+	return v[0] + (v[1]-v[0])*s.amount(speed)
+}
+
+// WidthFactor 返回速度对应的宽度系数，未启用时为 1
+func (s *SpeedStyle) WidthFactor(speed float64) float64 {
+	if !s.Enabled {
+		return 1
+	}
+	return s.lerp(s.Width, speed)
+}
+
+// AlphaFactor 返回速度对应的透明度系数，未启用时为 1
+func (s *SpeedStyle) AlphaFactor(speed float64) float64 {
+	if !s.Enabled {
+		return 1
+	}
+	return s.lerp(s.Alpha, speed)
+}
+
+// BrightenAmount 返回速度对应的提亮比例，未启用时为 0
+func (s *SpeedStyle) BrightenAmount(speed float64) float64 {
+	if !s.Enabled {
+		return 0
+	}
+	return math.Max(0, math.Min(1, s.lerp(s.Brighten, speed)))
+}
+
+// SpacingAt 返回速度对应的采样间距，未启用时为旧版固定的 2 像素
+func (s *SpeedStyle) SpacingAt(speed float64) float64 {
+	if !s.Enabled {
+		return defaultPointSpacing
+	}
+	return math.Max(0.5, s.lerp(s.Spacing, speed))
+}
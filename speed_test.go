@@ -0,0 +1,70 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSpeedStyleMapping(t *testing.T) {
+	s := defaultSpeedStyle()
+	s.Enabled = true
+	s.MinSpeed, s.MaxSpeed = 200, 1200
+	s.Width = [2]float64{1, 0.5}
+	s.Alpha = [2]float64{0.6, 1}
+	s.Brighten = [2]float64{-0.5, 1.5} // 超出 0-1 的部分会被截断
+	s.Spacing = [2]float64{4, 0}       // 间距不小于 0.5
+
+	tests := []struct {
+		speed                           float64
+		width, alpha, brighten, spacing float64
+	}{
+		// MinSpeed 以下按慢速处理
+		{0, 1, 0.6, 0, 4},
+		{200, 1, 0.6, 0, 4},
+		// 之间线性插值
+		{450, 0.875, 0.7, 0, 3},
+		{700, 0.75, 0.8, 0.5, 2},
+		{950, 0.625, 0.9, 1, 1},
+		// MaxSpeed 以上按快速处理
+		{1200, 0.5, 1, 1, 0.5},
+		{5000, 0.5, 1, 1, 0.5},
+	}
+	for _, tt := range tests {
+		got := [4]float64{s.WidthFactor(tt.speed), s.AlphaFactor(tt.speed), s.BrightenAmount(tt.speed), s.SpacingAt(tt.speed)}
+		want := [4]float64{tt.width, tt.alpha, tt.brighten, tt.spacing}
+		for i := range got {
+			if math.Abs(got[i]-want[i]) > 1e-9 {
+				t.Errorf("speed %v: width, alpha, brighten, spacing = %v, want %v", tt.speed, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestSpeedStyleDegenerateRange(t *testing.T) {
+	// MaxSpeed 不大于 MinSpeed 时在 MaxSpeed 处直接切换
+	s := SpeedStyle{MinSpeed: 500, MaxSpeed: 500}
+	tests := []struct {
+		speed, want float64
+	}{
+		{0, 0},
+		{499, 0},
+		{500, 1},
+		{900, 1},
+	}
+	for _, tt := range tests {
+		if got := s.amount(tt.speed); got != tt.want {
+			t.Errorf("amount(%v) = %v, want %v", tt.speed, got, tt.want)
+		}
+	}
+}
+
+func TestSpeedStyleDisabled(t *testing.T) {
+	s := defaultSpeedStyle()
+	s.Enabled = false
+	for _, speed := range []float64{0, 1000, 10000} {
+		if w, a, b, sp := s.WidthFactor(speed), s.AlphaFactor(speed), s.BrightenAmount(speed), s.SpacingAt(speed); w != 1 || a != 1 || b != 0 || sp != defaultPointSpacing {
+			t.Errorf("speed %v disabled: %v %v %v %v, want 1 1 0 %v", speed, w, a, b, sp, defaultPointSpacing)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 聚光灯形状
+const (
+	SpotlightCircle = "circle" // 圆形
+	SpotlightRect   = "rect"   // 矩形
+)
+
+const (
+	// 开启和关闭时遮罩淡入淡出的时长 (秒)
+	spotlightTransition = 0.25
+	// 圆形聚光灯的边数
+	spotlightSegments = 64
+)
+
+// SpotlightConfig 演示聚光灯设置
+// 开启后整个覆盖层盖上一层半透明的暗色遮罩，只留出光标周围的区域
+type SpotlightConfig struct {
+	Enabled bool    `json:"enabled"`
+	Shape   string  `json:"shape"`   // "circle", "rect"
+	Radius  float64 `json:"radius"`  // 圆形的半径 (像素)
+	Width   float64 `json:"width"`   // 矩形的宽度 (像素)
+	Height  float64 `json:"height"`  // 矩形的高度 (像素)
+	Feather float64 `json:"feather"` // 边缘从透明过渡到遮罩的宽度 (像素)
+	Dim     float64 `json:"dim"`     // 遮罩的不透明度 (0 ~ 1)
+}
+
+func defaultSpotlightConfig() SpotlightConfig {
+	return SpotlightConfig{
+		Enabled: false,
+		Shape:   SpotlightCircle,
+		Radius:  180,
+		Width:   480,
+		Height:  270,
+		Feather: 60,
+		Dim:     0.6,
+	}
+}
+
+// spotlightState 聚光灯的淡入淡出进度，以及每帧复用的顶点和索引
+// 遮罩覆盖整个屏幕，与轨迹分开绘制，不计入轨迹的顶点数和辉光
+type spotlightState struct {
+	level    float64 // 0 = 关闭，1 = 完全显示
+	vertices []ebiten.Vertex
+	indices  []uint32
+}
+
+// updateSpotlight 推进淡入淡出动画，返回过渡是否仍在进行
+// 静止的遮罩不算活动，光标移动时轨迹本身会保持刷新率
+func (tm *TraceManager) updateSpotlight(dt float64) bool {
+	s := &tm.spotlight
+	target := 0.0
+	if tm.config.Spotlight.Enabled {
+		target = 1
+	}
+	step := dt / spotlightTransition
+	if s.level < target {
+		s.level = math.Min(target, s.level+step)
+	} else {
+		s.level = math.Max(target, s.level-step)
+	}
+	return s.level != target
+}
+
+// spotlightPoint 返回聚光灯轮廓上第 i 个点，grow 为轮廓向外扩展的距离
+// 圆形轮廓有 spotlightSegments 个点，矩形轮廓为 4 个角
+func spotlightPoint(cfg *SpotlightConfig, cx, cy float64, i int, grow float64) (float64, float64) {
+	if cfg.Shape == SpotlightRect {
+		corners := [4][2]float64{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}}
+		c := corners[i]
+		return cx + c[0]*(cfg.Width/2+grow), cy + c[1]*(cfg.Height/2+grow)
+	}
+	sin, cos := math.Sincos(float64(i) * 2 * math.Pi / spotlightSegments)
+	r := cfg.Radius + grow
+	return cx + r*cos, cy + r*sin
+}
+
+// buildSpotlight 生成 w x h 屏幕上的遮罩三角形
+// 由三圈轮廓组成：镂空边缘 (透明)、柔化边缘 (Dim) 和屏幕之外的远处轮廓 (Dim)
+func (tm *TraceManager) buildSpotlight(w, h int) bool {
+	s := &tm.spotlight
+	s.vertices = s.vertices[:0]
+	s.indices = s.indices[:0]
+	cfg := &tm.config.Spotlight
+	if s.level <= 0 || cfg.Dim <= 0 {
+		return false
+	}
+
+	n := spotlightSegments
+	if cfg.Shape == SpotlightRect {
+		n = 4
+	}
+	feather := math.Max(0, cfg.Feather)
+	// 远处轮廓离光标足够远，光标在屏幕外时遮罩也能覆盖整个屏幕
+	far := 2*math.Hypot(float64(w), float64(h)) + math.Abs(tm.lastX) + math.Abs(tm.lastY)
+
+	dim := float32(math.Min(1, cfg.Dim) * s.level)
+	rings := [3]struct {
+		grow  float64
+		alpha float32
+	}{{0, 0}, {feather, dim}, {far, dim}}
+	for _, ring := range rings {
+		c := vertexColor{0, 0, 0, ring.alpha} // 预乘的黑色
+		for i := 0; i < n; i++ {
+			x, y := spotlightPoint(cfg, tm.lastX, tm.lastY, i, ring.grow)
+			s.vertices = append(s.vertices, ebitenVertex(x, y, c))
+		}
+	}
+	// 相邻两圈之间连成三角形带
+	for r := 0; r < len(rings)-1; r++ {
+		inner, outer := uint32(r*n), uint32((r+1)*n)
+		for i := 0; i < n; i++ {
+			next := uint32((i + 1) % n)
+			a, b := inner+uint32(i), inner+next
+			c, d := outer+uint32(i), outer+next
+			s.indices = append(s.indices, a, c, b, b, c, d)
+		}
+	}
+	return true
+}
+
+// drawSpotlight 把遮罩绘制到 screen，应在轨迹之前调用
+func (tm *TraceManager) drawSpotlight(screen *ebiten.Image) {
+	w, h := screen.Bounds().Dx(), screen.Bounds().Dy()
+	if !tm.buildSpotlight(w, h) {
+		return
+	}
+	screen.DrawTriangles32(tm.spotlight.vertices, tm.spotlight.indices, tm.whiteImage, &ebiten.DrawTrianglesOptions{
+		Blend: maxBlend,
+	})
+}
+
+// drawSpotlightRGBA 软件渲染路径的 drawSpotlight
+func (tm *TraceManager) drawSpotlightRGBA(dst *image.RGBA) {
+	if !tm.buildSpotlight(dst.Bounds().Dx(), dst.Bounds().Dy()) {
+		return
+	}
+	RasterizeTriangles(dst, tm.spotlight.vertices, tm.spotlight.indices)
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"image"
 	"image/color"
 	"math"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -10,59 +12,196 @@ import (
 // TracePoint 轨迹上的单个点
 // 优化：使用值类型而非指针，减少 GC
 type TracePoint struct {
-	X, Y float64
-	Life float64 // 生命值 1.0 -> 0.0
+	X, Y  float64
+	Life  float64   // 生命值 1.0 -> 0.0
+	Time  time.Time // 采样时间
+	Speed float64   // 采样时的移动速度 (像素/秒)
 }
 
+const (
+	// defaultPointSpacing 采样新点的默认最小间距 (像素)
+	defaultPointSpacing = 2.0
+	// 修饰键标签相对点击位置的偏移 (像素)
+	rippleLabelOffset = 10
+	// 波纹的初始半径 (像素)
+	rippleStartRadius = 2.0
+)
+
 // Ripple 点击波纹
 type Ripple struct {
 	X, Y   float64
 	Radius float64
-	Life   float64 // 1.0 -> 0.0
+	Life   float64   // 1.0 -> 0.0
+	Time   time.Time // 创建时间，半径和生命值按经过的时间计算
+
+	// 创建时从按键样式解析出的参数
+	Shape    string
+	Color    [4]uint8 // alpha 为 0 表示使用轨迹颜色
+	Width    float64
+	Growth   float64
+	Duration float64
+	Rings    int // 同心圆环数量，双击为 2
+
+	// 按住修饰键点击时显示的标签，可能为 nil
+	Label *textLabel
 }
 
 // TraceManager 管理轨迹生成和渲染
 type TraceManager struct {
-	points     []TracePoint // 优化：值类型切片
+	points     []TracePoint  // 优化：值类型切片
+	smoothed   []TracePoint  // 平滑插值后的点，每帧复用
+	colors     []vertexColor // 与 smoothed 一一对应的颜色，每帧复用
+	gradient   gradient      // 渐变色标缓存
+	arcPos     []float64     // 每个点到头部的弧长比例，每帧复用
 	ripples    []Ripple
 	config     *Config
 	whiteImage *ebiten.Image
 
 	// 缓存切片，避免每帧分配
 	vertices []ebiten.Vertex
-	indices  []uint16
+	indices  []uint32
 
 	// 状态追踪
 	lastX, lastY float64
+
+	// 时间源，以及上一次 Update 的时间
+	clock      Clock
+	lastUpdate time.Time
+
+	// 光标移动速度 (像素/秒) 和速度向量
+	speed  float64
+	vx, vy float64
+
+	// 彩虹模式状态
+	rainbow Rainbow
+
+	// 手势图形：长按进度环、正在拖动的图形和松开后淡出的图形
+	holds       []holdMark
+	activeDrags []dragMark
+	drags       []dragMark
+
+	// 滚轮指示器
+	wheels []wheelMark
+
+	// 波纹标签使用的字体
+	labelFont labelFont
+
+	// 粒子效果
+	particles ParticleSystem
+
+	// 辉光离屏渲染
+	glow glowPass
+
+	// Kage 着色器，以及轨迹和波纹在 tm.indices 中的结束位置
+	shaders   shaderSet
+	trailEnd  int
+	rippleEnd int
+	startTime time.Time // 第一次 Update 的时间，用于着色器的 Time
+
+	// 纹理笔刷，以及本帧的轨迹是否使用了笔刷
+	brush       brushTexture
+	brushActive bool
+	widths      []float64 // 每个轨迹点的半宽，每帧复用
+
+	// 当前图层的轨迹点，每帧复用
+	layerPts []TracePoint
+
+	// 光标光环
+	halo haloState
+
+	// 晃动检测和定位环
+	shake   ShakeDetector
+	locator shakeLocator
+
+	// 演示聚光灯遮罩
+	spotlight spotlightState
+
+	// 屏幕批注
+	ink inkState
+
+	// 激光笔模式
+	laser laserState
 }
 
 // NewTraceManager 创建新的轨迹管理器
 func NewTraceManager(cfg *Config) *TraceManager {
-	img := ebiten.NewImage(1, 1)
-	img.Fill(color.White)
-
 	// 预分配容量，减少扩容
 	return &TraceManager{
-		points:     make([]TracePoint, 0, 200),
-		ripples:    make([]Ripple, 0, 20),
-		config:     cfg,
-		whiteImage: img,
-		vertices:   make([]ebiten.Vertex, 0, 1000),
-		indices:    make([]uint16, 0, 1000),
+		points:    make([]TracePoint, 0, 200),
+		ripples:   make([]Ripple, 0, 20),
+		config:    cfg,
+		clock:     systemClock{},
+		particles: newParticleSystem(),
+		vertices:  make([]ebiten.Vertex, 0, 1000),
+		indices:   make([]uint32, 0, 1000),
 	}
 }
 
-// AddRipple 添加一个点击波纹
-func (tm *TraceManager) AddRipple(x, y int) {
+// SetClock 替换时间源，用于测试或脚本回放
+func (tm *TraceManager) SetClock(c Clock) {
+	tm.clock = c
+	tm.lastUpdate = time.Time{}
+}
+
+// AddRipple 添加一个点击波纹，样式取自对应按键的配置，mods 为点击时按住的修饰键
+func (tm *TraceManager) AddRipple(x, y int, button MouseButton, mods Modifier) {
+	tm.addRipple(x, y, button, mods, 1)
+}
+
+// AddDoubleRipple 添加一个双圆环波纹，用于双击
+func (tm *TraceManager) AddDoubleRipple(x, y int, button MouseButton, mods Modifier) {
+	tm.addRipple(x, y, button, mods, 2)
+}
+
+func (tm *TraceManager) addRipple(x, y int, button MouseButton, mods Modifier, rings int) {
 	if !tm.config.IsRipple {
 		return
 	}
-	tm.ripples = append(tm.ripples, Ripple{
-		X:      float64(x),
-		Y:      float64(y),
-		Radius: 2.0,
-		Life:   1.0,
-	})
+	style := tm.config.ButtonRipples.For(button)
+	if !style.Enabled {
+		return
+	}
+
+	ripple := Ripple{
+		X:        float64(x),
+		Y:        float64(y),
+		Radius:   rippleStartRadius,
+		Life:     1.0,
+		Time:     tm.clock.Now(),
+		Shape:    style.Shape,
+		Color:    style.Color,
+		Width:    style.Width,
+		Growth:   style.Growth,
+		Duration: style.Duration,
+		Rings:    rings,
+	}
+	// 未单独设置的参数沿用全局波纹设置
+	if ripple.Width <= 0 {
+		ripple.Width = tm.config.RippleWidth
+	}
+	if ripple.Growth <= 0 {
+		ripple.Growth = tm.config.RippleGrowth
+	}
+	if ripple.Duration <= 0 {
+		ripple.Duration = tm.config.RippleDuration
+	}
+
+	// 带修饰键的点击使用修饰键的颜色，并在旁边标出修饰键
+	if modStyle := &tm.config.ModifierRipples; modStyle.Enabled && mods != 0 {
+		if c, ok := modStyle.Color(mods); ok {
+			ripple.Color = c
+		}
+		if modStyle.ShowLabel {
+			tm.labelFont.ensure(tm.config.Keystrokes.FontPath, math.Max(6, modStyle.LabelSize))
+			ripple.Label = newTextLabel(&tm.labelFont, mods.String(), [4]uint8{255, 255, 255, 255}, labelBackground(ripple.Color))
+		}
+	}
+	tm.ripples = append(tm.ripples, ripple)
+}
+
+// AddClickParticles 在点击位置触发 click 粒子发射器
+func (tm *TraceManager) AddClickParticles(x, y int) {
+	tm.particles.Burst(tm.config.Particles, float64(x), float64(y))
 }
 
 // Update 更新轨迹点
@@ -70,6 +209,20 @@ func (tm *TraceManager) AddRipple(x, y int) {
 func (tm *TraceManager) Update(mx, my int) bool {
 	x, y := float64(mx), float64(my)
 
+	// 按实际经过的时间推进动画，与 TPS 无关
+	now := tm.clock.Now()
+	dt := elapsedSeconds(tm.lastUpdate, now)
+	tm.lastUpdate = now
+	if tm.startTime.IsZero() {
+		tm.startTime = now
+	}
+
+	// 光标移动速度
+	if dt > 0 {
+		tm.vx, tm.vy = (x-tm.lastX)/dt, (y-tm.lastY)/dt
+		tm.speed = math.Hypot(tm.vx, tm.vy)
+	}
+
 	moved := false
 	// 检查是否移动
 	if math.Abs(x-tm.lastX) > 0.1 || math.Abs(y-tm.lastY) > 0.1 {
@@ -79,24 +232,74 @@ func (tm *TraceManager) Update(mx, my int) bool {
 	}
 
 	// 添加新点逻辑
+	sampled := len(tm.points)
 	if len(tm.points) == 0 {
 		if moved {
-			tm.points = append(tm.points, TracePoint{X: x, Y: y, Life: 1.0})
+			tm.points = append(tm.points, TracePoint{X: x, Y: y, Life: 1.0, Time: now, Speed: tm.speed})
 		}
 	} else {
 		last := tm.points[len(tm.points)-1]
 		dist := math.Hypot(x-last.X, y-last.Y)
 
-		// 只有移动了一定距离才添加新点
-		if dist > 2.0 {
-			tm.points = append(tm.points, TracePoint{X: x, Y: y, Life: 1.0})
+		// 只有移动了一定距离才添加新点，间距可以随速度变化
+		if dist > tm.config.SpeedStyle.SpacingAt(last.Speed) {
+			// 按与上一个点的距离和时间差计算速度，并与上一个点做平滑，减少采样抖动
+			speed := tm.speed
+			if elapsed := elapsedSeconds(last.Time, now); elapsed > 0 {
+				speed = (last.Speed + dist/elapsed) / 2
+			}
+			tm.points = append(tm.points, TracePoint{X: x, Y: y, Life: 1.0, Time: now, Speed: speed})
+		}
+	}
+	// 新的采样点交给晃动检测
+	shake := tm.updateShake(tm.points[sampled:], dt)
+
+	tm.updateLaser(moved, now)
+	if len(tm.activeLayers()) > 0 {
+		// 各图层按采样时间计算生命值
+		tm.pruneHistory(now)
+	} else {
+		tm.decayPoints(now)
+	}
+
+	// 更新波纹
+	// 按创建后经过的时间计算，帧间隔再长也不会让刚创建的波纹少活一帧
+	activeRipples := 0
+	for i := range tm.ripples {
+		age := elapsedSeconds(tm.ripples[i].Time, now)
+		tm.ripples[i].Radius = rippleStartRadius + tm.ripples[i].Growth*age // 扩散速度
+		tm.ripples[i].Life = lifeAt(age, tm.ripples[i].Duration)            // 消失速度
+		if tm.ripples[i].Life > 0 {
+			if activeRipples != i {
+				tm.ripples[activeRipples] = tm.ripples[i]
+			}
+			activeRipples++
+		} else if tm.ripples[i].Label != nil {
+			tm.ripples[i].Label.Release()
 		}
 	}
+	tm.ripples = tm.ripples[:activeRipples]
+
+	// 彩虹色相循环
+	if tm.config.IsRainbow {
+		tm.rainbow.Advance(tm.config, dt, tm.speed)
+	}
+
+	gestures := tm.updateGestureMarks(dt)
+	wheels := tm.updateWheelMarks(dt)
+	particles := tm.particles.Update(tm.config.Particles, dt, x, y, tm.vx, tm.vy, tm.points)
+	halo := tm.updateHalo(x, y, dt)
+	spotlight := tm.updateSpotlight(dt)
+	ink := tm.updateInk(x, y, now, dt)
 
+	return len(tm.points) > 0 || len(tm.ripples) > 0 || gestures || wheels || particles || halo || shake || spotlight || ink
+}
+
+// decayPoints 按采样后经过的时间和 TailLifetime 计算每个点的生命值，移除死亡的点和超出 TailLength 的点
+func (tm *TraceManager) decayPoints(now time.Time) {
 	// 原地更新并过滤死亡点
 	// 双指针法
 	writeIdx := 0
-	decay := 1.0 - tm.config.DecaySpeed
 
 	// 预计算限制
 	maxPoints := tm.config.TailLength
@@ -107,7 +310,7 @@ func (tm *TraceManager) Update(mx, my int) bool {
 
 	for i := startIdx; i < len(tm.points); i++ {
 		// 直接修改切片中的元素
-		tm.points[i].Life -= decay
+		tm.points[i].Life = lifeAt(elapsedSeconds(tm.points[i].Time, now), tm.config.TailLifetime)
 		if tm.points[i].Life > 0 {
 			// 如果需要移动元素 (即前面有被删除的元素)
 			if writeIdx != i {
@@ -118,22 +321,198 @@ func (tm *TraceManager) Update(mx, my int) bool {
 	}
 	// 裁剪切片
 	tm.points = tm.points[:writeIdx]
+}
 
-	// 更新波纹
-	activeRipples := 0
-	for i := range tm.ripples {
-		tm.ripples[i].Radius += tm.config.RippleGrowthSpeed // 扩散速度
-		tm.ripples[i].Life -= tm.config.RippleDecaySpeed    // 消失速度
-		if tm.ripples[i].Life > 0 {
-			if activeRipples != i {
-				tm.ripples[activeRipples] = tm.ripples[i]
-			}
-			activeRipples++
+// vertexColor 预乘 alpha 的顶点颜色
+type vertexColor struct {
+	R, G, B, A float32
+}
+
+// baseColor 返回当前用于波纹和单色轨迹的颜色 (未预乘)
+// 彩虹模式下为彩虹在头部的颜色，Config.TailColor 本身不会被修改
+func (tm *TraceManager) baseColor() (r, g, b, a float32) {
+	a = float32(tm.config.TailColor[3]) / 255
+	if tm.config.IsRainbow {
+		rr, gg, bb := tm.rainbow.At(tm.config, 0)
+		return float32(rr), float32(gg), float32(bb), a
+	}
+	r = float32(tm.config.TailColor[0]) / 255
+	g = float32(tm.config.TailColor[1]) / 255
+	b = float32(tm.config.TailColor[2]) / 255
+	return r, g, b, a
+}
+
+// styleColor 把按键样式颜色转换为预乘的顶点颜色，alpha 为 0 时使用 baseColor
+// fade 为额外的透明度系数
+func (tm *TraceManager) styleColor(c [4]uint8, fade float64) vertexColor {
+	r, g, b, a := tm.baseColor()
+	if c[3] != 0 {
+		r = float32(c[0]) / 255
+		g = float32(c[1]) / 255
+		b = float32(c[2]) / 255
+		a = float32(c[3]) / 255
+	}
+	a *= float32(fade)
+	return vertexColor{r * a, g * a, b * a, a}
+}
+
+// ebitenVertex 生成纯色顶点
+func ebitenVertex(x, y float64, c vertexColor) ebiten.Vertex {
+	return ebiten.Vertex{
+		DstX:   float32(x),
+		DstY:   float32(y),
+		ColorR: c.R, ColorG: c.G, ColorB: c.B, ColorA: c.A,
+	}
+}
+
+// trailWidths 计算每个轨迹点的半宽到 tm.widths，width 为图层的基础宽度
+func (tm *TraceManager) trailWidths(points []TracePoint, width float64) []float64 {
+	tm.widths = tm.widths[:0]
+	for _, p := range points {
+		w := width * tm.config.WidthProfile.Eval(p.Life) * tm.config.SpeedStyle.WidthFactor(p.Speed)
+		tm.widths = append(tm.widths, w)
+	}
+	return tm.widths
+}
+
+// trailVertex 生成轨迹顶点，custom 参数供着色器使用：
+// 生命值、速度、沿路径的位置 u 和横向位置 v (-1 ~ 1)
+func trailVertex(x, y float64, p *TracePoint, u, v float64, c vertexColor) ebiten.Vertex {
+	return ebiten.Vertex{
+		DstX:   float32(x),
+		DstY:   float32(y),
+		ColorR: c.R, ColorG: c.G, ColorB: c.B, ColorA: c.A,
+		Custom0: float32(p.Life),
+		Custom1: float32(p.Speed),
+		Custom2: float32(u),
+		Custom3: float32(v),
+	}
+}
+
+// arcPositions 计算每个点到头部的弧长比例 (0 = 头部，1 = 尾端) 到 tm.arcPos
+// points 从尾端 (最旧) 排列到头部 (最新)
+func (tm *TraceManager) arcPositions(points []TracePoint) []float64 {
+	tm.arcPos = tm.arcPos[:0]
+
+	total := 0.0
+	for i := 1; i < len(points); i++ {
+		total += math.Hypot(points[i].X-points[i-1].X, points[i].Y-points[i-1].Y)
+	}
+
+	// 从尾端开始累计弧长
+	dist := 0.0
+	for i, p := range points {
+		if i > 0 {
+			dist += math.Hypot(p.X-points[i-1].X, p.Y-points[i-1].Y)
+		}
+		u := 0.0
+		if total > 0 {
+			u = (total - dist) / total
 		}
+		tm.arcPos = append(tm.arcPos, u)
 	}
-	tm.ripples = tm.ripples[:activeRipples]
+	return tm.arcPos
+}
 
-	return len(tm.points) > 0 || len(tm.ripples) > 0
+// computeColors 计算每个轨迹点的颜色到 tm.colors
+// 图层设置了渐变或颜色时使用图层的渐变或颜色；否则彩虹模式下按彩虹设置取色，
+// 配置了渐变时按到头部的弧长比例在 OKLab 中插值 (透明度取自色标)，再否则使用 TailColor。
+// 之后按速度映射提亮，透明度再乘以图层透明度、透明度曲线和速度系数
+func (tm *TraceManager) computeColors(points []TracePoint, layer *TrailLayer) {
+	tm.colors = tm.colors[:0]
+	cfg := tm.config
+	stops := cfg.TailGradient
+	if len(layer.Gradient) > 0 {
+		stops = layer.Gradient
+	}
+	layerColor := len(layer.Gradient) == 0 && layer.Color[3] != 0
+	useRainbow := cfg.IsRainbow && len(layer.Gradient) == 0 && !layerColor
+	useGradient := !useRainbow && !layerColor && len(stops) > 0
+
+	var arc []float64
+	if useRainbow || useGradient {
+		arc = tm.arcPositions(points)
+	}
+	if useGradient {
+		tm.gradient = newGradient(tm.gradient, stops)
+	}
+
+	baseR, baseG, baseB, baseA := tm.baseColor()
+	if layerColor {
+		c := layer.Color
+		baseR, baseG, baseB, baseA = float32(c[0])/255, float32(c[1])/255, float32(c[2])/255, float32(c[3])/255
+	}
+	for i, p := range points {
+		r, g, b, a := float64(baseR), float64(baseG), float64(baseB), float64(baseA)
+		switch {
+		case useRainbow:
+			r, g, b = tm.rainbow.At(cfg, arc[i])
+		case useGradient:
+			r, g, b, a = tm.gradient.At(arc[i])
+		}
+
+		// 速度映射：越快越亮 (向白色混合)
+		if k := cfg.SpeedStyle.BrightenAmount(p.Speed); k > 0 {
+			r += (1 - r) * k
+			g += (1 - g) * k
+			b += (1 - b) * k
+		}
+
+		a *= layer.Opacity * cfg.AlphaProfile.Eval(p.Life) * cfg.SpeedStyle.AlphaFactor(p.Speed)
+		a = math.Max(0, math.Min(1, a))
+		tm.colors = append(tm.colors, vertexColor{float32(r * a), float32(g * a), float32(b * a), float32(a)})
+	}
+}
+
+// addRing 添加一个圆环 (或正多边形环)，radius 为内半径
+func (tm *TraceManager) addRing(x, y, radius, thickness float64, shape string, c vertexColor) {
+	sides, phase := ringPolygon(shape)
+
+	rIn := radius
+	// 多边形的外顶点沿对角方向外扩，保证每条边的厚度都是 thickness
+	rOut := radius + thickness/math.Cos(math.Pi/float64(sides))
+
+	startIndex := uint32(len(tm.vertices))
+
+	for i := 0; i <= sides; i++ {
+		angle := phase + float64(i)*2*math.Pi/float64(sides)
+		sin, cos := math.Sincos(angle)
+
+		// 着色器参数：角度比例，以及内侧 -1 / 外侧 1
+		u := float32(i) / float32(sides)
+
+		// Inner vertex
+		tm.vertices = append(tm.vertices, ebiten.Vertex{
+			DstX:   float32(x + rIn*cos),
+			DstY:   float32(y + rIn*sin),
+			ColorR: c.R, ColorG: c.G, ColorB: c.B, ColorA: c.A,
+			Custom2: u, Custom3: -1,
+		})
+
+		// Outer vertex
+		tm.vertices = append(tm.vertices, ebiten.Vertex{
+			DstX:   float32(x + rOut*cos),
+			DstY:   float32(y + rOut*sin),
+			ColorR: c.R, ColorG: c.G, ColorB: c.B, ColorA: c.A,
+			Custom2: u, Custom3: 1,
+		})
+	}
+
+	for i := 0; i < sides; i++ {
+		idx := startIndex + uint32(i*2)
+		tm.indices = append(tm.indices, idx, idx+1, idx+2, idx+1, idx+3, idx+2)
+	}
+}
+
+// maxBlend 使用 Max 混合模式解决重叠部分颜色变深的问题
+// 当半透明的圆角和线段重叠时，Max 模式会取最大透明度而不是叠加，从而保持颜色均匀
+var maxBlend = ebiten.Blend{
+	BlendFactorSourceRGB:        ebiten.BlendFactorOne,
+	BlendFactorDestinationRGB:   ebiten.BlendFactorOne,
+	BlendOperationRGB:           ebiten.BlendOperationMax,
+	BlendFactorSourceAlpha:      ebiten.BlendFactorOne,
+	BlendFactorDestinationAlpha: ebiten.BlendFactorOne,
+	BlendOperationAlpha:         ebiten.BlendOperationMax,
 }
 
 // Draw 绘制轨迹
@@ -141,179 +520,319 @@ func (tm *TraceManager) Draw(screen *ebiten.Image) {
 	// 透明清屏，避免整屏黑底
 	screen.Fill(color.RGBA{0, 0, 0, 0})
 
-	if len(tm.points) < 2 && len(tm.ripples) == 0 {
+	if tm.whiteImage == nil {
+		// 延迟创建，保证纯软件渲染路径不需要 GPU
+		tm.whiteImage = ebiten.NewImage(1, 1)
+		tm.whiteImage.Fill(color.White)
+	}
+
+	// 聚光灯遮罩在最下层，批注在轨迹之下
+	tm.drawSpotlight(screen)
+	tm.drawInk(screen)
+
+	if !tm.buildGeometry() {
 		return
 	}
 
-	// 复用切片
-	tm.vertices = tm.vertices[:0]
-	tm.indices = tm.indices[:0]
+	// 辉光画在清晰的轨迹下方
+	if glow := tm.glowConfig(); glow.Enabled {
+		tm.glow.Draw(screen, glow, tm.vertices, func(dst *ebiten.Image, vertices []ebiten.Vertex) {
+			tm.drawTriangles(dst, vertices, false)
+		})
+	} else {
+		tm.glow.Release()
+	}
 
-	// 预计算颜色分量，避免循环中重复计算
-	r := float32(tm.config.TailColor[0]) / 255
-	g := float32(tm.config.TailColor[1]) / 255
-	b := float32(tm.config.TailColor[2]) / 255
-	a := float32(tm.config.TailColor[3]) / 255
+	tm.drawTriangles(screen, tm.vertices, true)
 
-	// 1. 绘制轨迹
-	if len(tm.points) >= 2 {
-		width := tm.config.TailWidth
+	tm.eachLabel(func(l *textLabel, x, y int, alpha float64) {
+		l.Draw(screen, x, y, alpha)
+	})
+}
 
-		// 辅助函数：绘制实心圆 (用于平滑连接处)
-		addCircle := func(x, y, radius float64, red, green, blue, alpha float32) {
-			if radius < 0.5 {
-				return
-			}
-			const circleSegments = 12
-			centerIdx := uint16(len(tm.vertices))
-
-			// Center vertex
-			tm.vertices = append(tm.vertices, ebiten.Vertex{
-				DstX:   float32(x),
-				DstY:   float32(y),
-				ColorR: red, ColorG: green, ColorB: blue, ColorA: alpha,
-			})
-
-			for i := 0; i <= circleSegments; i++ {
-				angle := float64(i) * 2 * math.Pi / circleSegments
-				sin, cos := math.Sincos(angle)
-				tm.vertices = append(tm.vertices, ebiten.Vertex{
-					DstX:   float32(x + radius*cos),
-					DstY:   float32(y + radius*sin),
-					ColorR: red, ColorG: green, ColorB: blue, ColorA: alpha,
-				})
-			}
+// DrawRGBA 使用软件光栅化把轨迹绘制到 dst，不需要窗口和 GPU
+// 与 Draw 使用相同的顶点和索引，混合方式同样为 Max，辉光改用盒式模糊近似
+func (tm *TraceManager) DrawRGBA(dst *image.RGBA) {
+	// 透明清屏
+	clear(dst.Pix)
 
-			for i := 0; i < circleSegments; i++ {
-				// center, current, next
-				tm.indices = append(tm.indices, centerIdx, centerIdx+1+uint16(i), centerIdx+1+uint16(i+1))
-			}
-		}
+	tm.drawSpotlightRGBA(dst)
+	tm.drawInkRGBA(dst)
 
-		for i := 0; i < len(tm.points)-1; i++ {
-			// 使用指针访问以避免复制大结构体（虽然这里结构体很小）
-			p1 := &tm.points[i]
-			p2 := &tm.points[i+1]
+	if !tm.buildGeometry() {
+		return
+	}
 
-			// 计算方向向量
-			dx := p2.X - p1.X
-			dy := p2.Y - p1.Y
-			l := math.Hypot(dx, dy)
-			if l == 0 {
-				continue
-			}
+	if glow := tm.glowConfig(); glow.Enabled {
+		tm.glow.DrawRGBA(dst, glow, tm.rasterize)
+	}
+	tm.rasterize(dst)
 
-			// 归一化并旋转90度得到法向量
-			nx := -dy / l
-			ny := dx / l
+	tm.eachLabel(func(l *textLabel, x, y int, alpha float64) {
+		l.DrawRGBA(dst, x, y, alpha)
+	})
+}
 
-			// 计算宽度
-			w1 := width * p1.Life
-			w2 := width * p2.Life
+// drawTriangles 把 vertices / tm.indices 绘制到 dst
+// 使用笔刷或着色器的轨迹、使用着色器的波纹单独绘制，其余部分合并为一次纯色绘制
+// useShaders 为 false 时不使用着色器 (辉光的离屏绘制)
+func (tm *TraceManager) drawTriangles(dst *ebiten.Image, vertices []ebiten.Vertex, useShaders bool) {
+	var trailShader, rippleShader *ebiten.Shader
+	if useShaders {
+		cfg := &tm.config.Shaders
+		if !tm.config.Laser.Enabled {
+			trailShader = tm.shaders.trail.get(cfg.Dir, cfg.Trail)
+		}
+		rippleShader = tm.shaders.ripple.get(cfg.Dir, cfg.Ripple)
+	}
+	var brush *ebiten.Image
+	if tm.brushActive {
+		brush = tm.brush.image()
+	}
 
-			// 计算颜色 alpha
-			// 优化：只乘一次 alpha
-			c1A := a * float32(p1.Life)
-			c2A := a * float32(p2.Life)
+	flatOptions := &ebiten.DrawTrianglesOptions{
+		Blend:     maxBlend,
+		AntiAlias: false, // 关闭抗锯齿以提高性能
+	}
+	flat := tm.flatIndices(trailShader == nil && brush == nil, rippleShader == nil)
+	if len(flat) > 0 {
+		dst.DrawTriangles32(vertices, flat, tm.whiteImage, flatOptions)
+	}
 
-			// P1 Left
-			v1 := ebiten.Vertex{
-				DstX:   float32(p1.X + nx*w1),
-				DstY:   float32(p1.Y + ny*w1),
-				ColorR: r * c1A, ColorG: g * c1A, ColorB: b * c1A, ColorA: c1A,
-			}
-			// P1 Right
-			v2 := ebiten.Vertex{
-				DstX:   float32(p1.X - nx*w1),
-				DstY:   float32(p1.Y - ny*w1),
-				ColorR: r * c1A, ColorG: g * c1A, ColorB: b * c1A, ColorA: c1A,
-			}
-			// P2 Left
-			v3 := ebiten.Vertex{
-				DstX:   float32(p2.X + nx*w2),
-				DstY:   float32(p2.Y + ny*w2),
-				ColorR: r * c2A, ColorG: g * c2A, ColorB: b * c2A, ColorA: c2A,
-			}
-			// P2 Right
-			v4 := ebiten.Vertex{
-				DstX:   float32(p2.X - nx*w2),
-				DstY:   float32(p2.Y - ny*w2),
-				ColorR: r * c2A, ColorG: g * c2A, ColorB: b * c2A, ColorA: c2A,
-			}
+	trailIndices := tm.indices[:tm.trailEnd]
+	rippleIndices := tm.indices[tm.trailEnd:tm.rippleEnd]
+	if trailShader == nil && brush != nil && len(trailIndices) > 0 {
+		dst.DrawTriangles32(vertices, trailIndices, brush, &ebiten.DrawTrianglesOptions{
+			Blend:   maxBlend,
+			Filter:  ebiten.FilterLinear,
+			Address: ebiten.AddressRepeat,
+		})
+	}
+	if trailShader == nil && rippleShader == nil {
+		return
+	}
 
-			baseIndex := uint16(len(tm.vertices))
-			tm.vertices = append(tm.vertices, v1, v2, v3, v4)
-			tm.indices = append(tm.indices, baseIndex, baseIndex+1, baseIndex+2, baseIndex+1, baseIndex+3, baseIndex+2)
+	r, g, b, a := tm.baseColor()
+	op := &ebiten.DrawTrianglesShaderOptions{
+		Blend:    maxBlend,
+		Uniforms: shaderUniforms(float32(tm.lastUpdate.Sub(tm.startTime).Seconds()), float32(tm.speed), [4]float32{r, g, b, a}),
+	}
+	if trailShader != nil && len(trailIndices) > 0 {
+		// 笔刷纹理作为着色器的第一张图像
+		op.Images[0] = brush
+		dst.DrawTrianglesShader32(vertices, trailIndices, trailShader, op)
+		op.Images[0] = nil
+	}
+	if rippleShader != nil && len(rippleIndices) > 0 {
+		dst.DrawTrianglesShader32(vertices, rippleIndices, rippleShader, op)
+	}
+}
 
-			// 在 p1 处绘制圆角连接
-			addCircle(p1.X, p1.Y, w1, r*c1A, g*c1A, b*c1A, c1A)
+// flatIndices 返回使用纯色绘制的索引，trail / ripple 表示轨迹和波纹是否包含在内
+func (tm *TraceManager) flatIndices(trail, ripple bool) []uint32 {
+	if trail && ripple {
+		return tm.indices
+	}
+	flat := append(tm.shaders.flat[:0], tm.indices[tm.rippleEnd:]...)
+	if trail {
+		flat = append(flat, tm.indices[:tm.trailEnd]...)
+	}
+	if ripple {
+		flat = append(flat, tm.indices[tm.trailEnd:tm.rippleEnd]...)
+	}
+	tm.shaders.flat = flat
+	return flat
+}
+
+// rasterize 用软件光栅化绘制 tm.vertices / tm.indices，笔刷轨迹采样笔刷纹理
+func (tm *TraceManager) rasterize(dst *image.RGBA) {
+	if !tm.brushActive {
+		RasterizeTriangles(dst, tm.vertices, tm.indices)
+		return
+	}
+	RasterizeTriangles(dst, tm.vertices, tm.flatIndices(false, true))
+	RasterizeTexturedTriangles(dst, tm.vertices, tm.indices[:tm.trailEnd], tm.brush.img)
+}
+
+// eachLabel 对每个波纹标签调用 draw，标签位于点击位置的右上方，随波纹淡出
+func (tm *TraceManager) eachLabel(draw func(l *textLabel, x, y int, alpha float64)) {
+	for _, ripple := range tm.ripples {
+		if ripple.Label == nil {
+			continue
 		}
+		size := ripple.Label.Size()
+		x := int(ripple.X) + rippleLabelOffset
+		y := int(ripple.Y) - rippleLabelOffset - size.Y
+		draw(ripple.Label, x, y, math.Min(1, ripple.Life*2))
+	}
+}
 
-		// 在最后一个点绘制圆角端点
-		lastP := &tm.points[len(tm.points)-1]
-		lastW := width * lastP.Life
-		lastAlpha := a * float32(lastP.Life)
-		addCircle(lastP.X, lastP.Y, lastW, r*lastAlpha, g*lastAlpha, b*lastAlpha, lastAlpha)
+// labelBackground 修饰键标签的背景色，跟随波纹颜色
+func labelBackground(c [4]uint8) [4]uint8 {
+	if c[3] == 0 {
+		return [4]uint8{30, 30, 30, 200}
 	}
+	return [4]uint8{c[0], c[1], c[2], 220}
+}
 
-	// 2. 绘制波纹 (圆环)
-	const segments = 20 // 降低分段数以优化性能
-	thickness := tm.config.RippleWidth
-	if thickness <= 0 {
-		thickness = 2.0
+// buildTrail 生成一条轨迹 (一个图层) 的三角形，points 从尾端排列到头部
+// 先在采样点之间插值出平滑曲线，再生成四边形
+func (tm *TraceManager) buildTrail(pts []TracePoint, layer *TrailLayer) {
+	tm.smoothed = smoothTrail(tm.smoothed, pts, tm.config.Smoothing, tm.config.SmoothingTension)
+	points := tm.smoothed
+	if len(points) < 2 {
+		return
+	}
+	width := tm.config.TailWidth * layer.WidthScale
+	tm.computeColors(points, layer)
+	if tm.brushActive {
+		tm.buildBrushTrail(points, tm.trailWidths(points, width), tm.arcPositions(points), width)
+		return
 	}
 
-	for _, ripple := range tm.ripples {
-		baseAlpha := float32(ripple.Life) * a
-		if baseAlpha <= 0 {
+	// 沿路径的位置，供着色器使用
+	tm.addPolyline(points, tm.trailWidths(points, width), tm.colors, tm.arcPositions(points))
+}
+
+// addPolyline 生成一条带圆角连接和圆角端点的折线，points 从尾端排列到头部
+// widths 为每个点的半宽，colors 为每个点预乘后的颜色，arc 为每个点沿路径的位置
+func (tm *TraceManager) addPolyline(points []TracePoint, widths []float64, colors []vertexColor, arc []float64) {
+	if len(points) == 0 {
+		return
+	}
+	for i := 0; i < len(points)-1; i++ {
+		// 使用指针访问以避免复制大结构体（虽然这里结构体很小）
+		p1 := &points[i]
+		p2 := &points[i+1]
+
+		// 计算方向向量
+		dx := p2.X - p1.X
+		dy := p2.Y - p1.Y
+		l := math.Hypot(dx, dy)
+		if l == 0 {
 			continue
 		}
 
-		rIn := ripple.Radius
-		rOut := ripple.Radius + thickness
+		// 归一化并旋转90度得到法向量
+		nx := -dy / l
+		ny := dx / l
 
-		centerIndex := uint16(len(tm.vertices))
+		w1, w2 := widths[i], widths[i+1]
 
-		for i := 0; i <= segments; i++ {
-			angle := float64(i) * 2 * math.Pi / segments
-			sin, cos := math.Sincos(angle)
+		// 预乘 alpha 后的颜色
+		c1 := colors[i]
+		c2 := colors[i+1]
 
-			// Inner vertex
-			tm.vertices = append(tm.vertices, ebiten.Vertex{
-				DstX:   float32(ripple.X + rIn*cos),
-				DstY:   float32(ripple.Y + rIn*sin),
-				ColorR: r * baseAlpha, ColorG: g * baseAlpha, ColorB: b * baseAlpha, ColorA: baseAlpha,
-			})
+		// P1 Left
+		v1 := trailVertex(p1.X+nx*w1, p1.Y+ny*w1, p1, arc[i], -1, c1)
+		// P1 Right
+		v2 := trailVertex(p1.X-nx*w1, p1.Y-ny*w1, p1, arc[i], 1, c1)
+		// P2 Left
+		v3 := trailVertex(p2.X+nx*w2, p2.Y+ny*w2, p2, arc[i+1], -1, c2)
+		// P2 Right
+		v4 := trailVertex(p2.X-nx*w2, p2.Y-ny*w2, p2, arc[i+1], 1, c2)
 
-			// Outer vertex
-			tm.vertices = append(tm.vertices, ebiten.Vertex{
-				DstX:   float32(ripple.X + rOut*cos),
-				DstY:   float32(ripple.Y + rOut*sin),
-				ColorR: r * baseAlpha, ColorG: g * baseAlpha, ColorB: b * baseAlpha, ColorA: baseAlpha,
-			})
-		}
+		baseIndex := uint32(len(tm.vertices))
+		tm.vertices = append(tm.vertices, v1, v2, v3, v4)
+		tm.indices = append(tm.indices, baseIndex, baseIndex+1, baseIndex+2, baseIndex+1, baseIndex+3, baseIndex+2)
+
+		// 在 p1 处绘制圆角连接
+		tm.addJoin(p1, arc[i], w1, c1)
+	}
+
+	// 在最后一个点绘制圆角端点
+	last := len(points) - 1
+	tm.addJoin(&points[last], arc[last], widths[last], colors[last])
+}
+
+// addJoin 绘制实心圆，用于平滑连接处和端点
+func (tm *TraceManager) addJoin(p *TracePoint, u, radius float64, c vertexColor) {
+	x, y := p.X, p.Y
+	if radius < 0.5 {
+		return
+	}
+	const circleSegments = 12
+	centerIdx := uint32(len(tm.vertices))
 
-		for i := 0; i < segments; i++ {
-			idx := centerIndex + uint16(i*2)
-			tm.indices = append(tm.indices, idx, idx+1, idx+2, idx+1, idx+3, idx+2)
+	// Center vertex
+	tm.vertices = append(tm.vertices, trailVertex(x, y, p, u, 0, c))
+
+	for i := 0; i <= circleSegments; i++ {
+		angle := float64(i) * 2 * math.Pi / circleSegments
+		sin, cos := math.Sincos(angle)
+		tm.vertices = append(tm.vertices, trailVertex(x+radius*cos, y+radius*sin, p, u, 1, c))
+	}
+
+	for i := 0; i < circleSegments; i++ {
+		// center, current, next
+		tm.indices = append(tm.indices, centerIdx, centerIdx+1+uint32(i), centerIdx+1+uint32(i+1))
+	}
+}
+
+// buildGeometry 生成轨迹和波纹的三角形到 tm.vertices / tm.indices
+// 返回 false 表示没有需要绘制的内容
+func (tm *TraceManager) buildGeometry() bool {
+	// 复用切片
+	tm.vertices = tm.vertices[:0]
+	tm.indices = tm.indices[:0]
+
+	// 1. 绘制轨迹，配置了图层时按顺序绘制每个图层
+	// 激光笔模式使用自己的预设图层，不使用笔刷和轨迹着色器
+	tm.brushActive = !tm.config.Laser.Enabled && tm.brush.load(tm.config.Brush.Path)
+	if layers := tm.activeLayers(); len(layers) > 0 {
+		for _, l := range layers {
+			if !l.Enabled {
+				continue
+			}
+			l = l.resolve(tm.config)
+			tm.buildTrail(tm.layerPoints(&l, tm.lastUpdate), &l)
 		}
+	} else {
+		l := TrailLayer{}.resolve(tm.config)
+		tm.buildTrail(tm.points, &l)
 	}
+	tm.trailEnd = len(tm.indices)
 
-	if len(tm.vertices) > 0 {
-		// 使用 Max 混合模式解决重叠部分颜色变深的问题
-		// 当半透明的圆角和线段重叠时，Max 模式会取最大透明度而不是叠加，从而保持颜色均匀
-		blend := ebiten.Blend{
-			BlendFactorSourceRGB:        ebiten.BlendFactorOne,
-			BlendFactorDestinationRGB:   ebiten.BlendFactorOne,
-			BlendOperationRGB:           ebiten.BlendOperationMax,
-			BlendFactorSourceAlpha:      ebiten.BlendFactorOne,
-			BlendFactorDestinationAlpha: ebiten.BlendFactorOne,
-			BlendOperationAlpha:         ebiten.BlendOperationMax,
+	// 2. 绘制波纹 (圆环)
+	for _, ripple := range tm.ripples {
+		c := tm.styleColor(ripple.Color, ripple.Life)
+		if c.A <= 0 {
+			continue
 		}
 
-		screen.DrawTriangles(tm.vertices, tm.indices, tm.whiteImage, &ebiten.DrawTrianglesOptions{
-			Blend:     blend,
-			AntiAlias: false, // 关闭抗锯齿以提高性能
-		})
+		thickness := ripple.Width
+		if thickness <= 0 {
+			thickness = 2.0
+		}
+		// 多个圆环向外依次排列
+		start := len(tm.vertices)
+		for i := 0; i < max(ripple.Rings, 1); i++ {
+			radius := ripple.Radius + float64(i)*thickness*doubleRingGap
+			tm.addRing(ripple.X, ripple.Y, radius, thickness, ripple.Shape, c)
+		}
+		for i := start; i < len(tm.vertices); i++ {
+			tm.vertices[i].Custom0 = float32(ripple.Life)
+		}
 	}
+	tm.rippleEnd = len(tm.indices)
+
+	// 3. 绘制手势图形
+	tm.buildGestureGeometry()
+
+	// 4. 绘制滚轮指示器
+	tm.buildWheelGeometry()
+
+	// 5. 绘制粒子
+	tm.buildParticleGeometry()
+
+	// 6. 绘制光标光环
+	tm.buildHaloGeometry()
+
+	// 7. 绘制晃动定位环
+	tm.buildShakeGeometry()
+
+	// 8. 绘制激光笔光点
+	tm.buildLaserGeometry()
+
+	return len(tm.vertices) > 0
 }
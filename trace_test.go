@@ -0,0 +1,111 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// testTPS 模拟的刷新率，包括空闲时降到的 5 和 15 TPS
+var testTPS = []int{5, 15, 60, 144}
+
+// advanceAt 以 tps 的刷新率推进 tm 共 d，光标停在 (x, y)，最后一帧补齐不足一帧的时间
+func advanceAt(tm *TraceManager, clock *ManualClock, tps int, d time.Duration, x, y int) {
+	step := time.Second / time.Duration(tps)
+	for d > 0 {
+		s := min(step, d)
+		clock.Advance(s)
+		tm.Update(x, y)
+		d -= s
+	}
+}
+
+// newClockedTraceManager 创建使用手动时钟的 TraceManager，并用第一次 Update 记录起始时间
+func newClockedTraceManager(cfg *Config) (*TraceManager, *ManualClock) {
+	tm := NewTraceManager(cfg)
+	clock := NewManualClock(time.Unix(0, 0))
+	tm.SetClock(clock)
+	tm.Update(0, 0)
+	return tm, clock
+}
+
+func TestRippleIndependentOfTPS(t *testing.T) {
+	for _, tps := range testTPS {
+		cfg := DefaultConfig()
+		cfg.IsRainbow = true
+		tm, clock := newClockedTraceManager(cfg)
+		tm.AddRipple(0, 0, MouseButtonLeft, 0)
+		advanceAt(tm, clock, tps, 200*time.Millisecond, 0, 0)
+
+		if len(tm.ripples) != 1 {
+			t.Fatalf("%d TPS: %d ripples after 0.2s, want 1", tps, len(tm.ripples))
+		}
+		r := tm.ripples[0]
+		// 初始半径 2，按 RippleGrowth 像素/秒扩散，RippleDuration 秒内消失
+		if want := 2 + cfg.RippleGrowth*0.2; math.Abs(r.Radius-want) > 1e-6 {
+			t.Errorf("%d TPS: radius = %v, want %v", tps, r.Radius, want)
+		}
+		if want := 1 - 0.2/cfg.RippleDuration; math.Abs(r.Life-want) > 1e-6 {
+			t.Errorf("%d TPS: life = %v, want %v", tps, r.Life, want)
+		}
+		if want := 0.2 / cfg.RainbowPeriod; math.Abs(tm.rainbow.phase-want) > 1e-6 {
+			t.Errorf("%d TPS: rainbow phase = %v, want %v", tps, tm.rainbow.phase, want)
+		}
+
+		advanceAt(tm, clock, tps, 200*time.Millisecond+time.Millisecond, 0, 0)
+		if len(tm.ripples) != 0 {
+			t.Errorf("%d TPS: ripple still alive after its duration", tps)
+		}
+	}
+}
+
+func TestTrailIndependentOfTPS(t *testing.T) {
+	for _, tps := range testTPS {
+		cfg := DefaultConfig()
+		tm, clock := newClockedTraceManager(cfg)
+		clock.Advance(time.Second / time.Duration(tps))
+		tm.Update(50, 0)
+		if len(tm.points) != 1 {
+			t.Fatalf("%d TPS: %d points after a move, want 1", tps, len(tm.points))
+		}
+
+		advanceAt(tm, clock, tps, 200*time.Millisecond, 50, 0)
+		if len(tm.points) != 1 {
+			t.Fatalf("%d TPS: %d points after 0.2s, want 1", tps, len(tm.points))
+		}
+		if want := 1 - 0.2/cfg.TailLifetime; math.Abs(tm.points[0].Life-want) > 1e-6 {
+			t.Errorf("%d TPS: life = %v, want %v", tps, tm.points[0].Life, want)
+		}
+
+		advanceAt(tm, clock, tps, 150*time.Millisecond, 50, 0)
+		if len(tm.points) != 0 {
+			t.Errorf("%d TPS: trail still alive after its lifetime", tps)
+		}
+	}
+}
+
+func TestScriptedInputClock(t *testing.T) {
+	// 同一段脚本按不同刷新率生成时间戳，波纹都按按下后经过的时间扩散和消失
+	for _, tps := range testTPS {
+		cfg := DefaultConfig()
+		in := NewScriptedInput(InputState{X: 20, Y: 20})
+		in.Interval = time.Second / time.Duration(tps)
+		in.Click(MouseButtonLeft)
+		in.Wait(tps / 10)
+		g := newScriptedGame(cfg, in)
+		runScript(g, in)
+
+		if len(g.traceManager.ripples) != 1 {
+			t.Fatalf("%d TPS: %d ripples, want 1", tps, len(g.traceManager.ripples))
+		}
+		// 第 0 帧为初始位置，第 1 帧按下
+		age := in.Now().Sub(in.Start.Add(in.Interval)).Seconds()
+		r := g.traceManager.ripples[0]
+		if want := rippleStartRadius + cfg.RippleGrowth*age; math.Abs(r.Radius-want) > 1e-6 {
+			t.Errorf("%d TPS: radius = %v, want %v", tps, r.Radius, want)
+		}
+		if want := 1 - age/cfg.RippleDuration; math.Abs(r.Life-want) > 1e-6 {
+			t.Errorf("%d TPS: life = %v, want %v", tps, r.Life, want)
+		}
+	}
+}
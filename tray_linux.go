@@ -0,0 +1,22 @@
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// RunTray 在 Linux 上没有托盘图标，改为等待 SIGINT / SIGTERM 后通知主程序退出
+// 配置请直接编辑 config.json
+func RunTray(cfg *Config, quitChan chan struct{}, actionChan chan OverlayAction) {
+	// 确保函数退出时通知主程序退出
+	defer close(quitChan)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	sig := <-sigChan
+	log.Println("Received", sig, "- exiting")
+}
@@ -32,17 +32,23 @@ const (
 	ID_TRAY = 1
 
 	// 菜单 ID
-	IDM_CONFIG = 1001
-	IDM_EXIT   = 1002
+	IDM_CONFIG    = 1001
+	IDM_EXIT      = 1002
+	IDM_SPOTLIGHT = 1003
+	IDM_INK_UNDO  = 1004
+	IDM_INK_CLEAR = 1005
+	IDM_LASER     = 1006
+	IDM_PAUSE     = 1007
 )
 
 // 全局变量用于通信
 var (
-	trayQuitChan       chan struct{}
-	trayOpenConfigChan chan struct{}
+	trayQuitChan   chan struct{}
+	trayActionChan chan OverlayAction
 )
 
-func RunTray(quitChan chan struct{}, openConfigChan chan struct{}) {
+// RunTray 运行托盘图标和消息循环，cfg 为托盘线程自己的配置副本，只用于注册全局热键
+func RunTray(cfg *Config, quitChan chan struct{}, actionChan chan OverlayAction) {
 	// 必须锁定 OS 线程，因为 Windows 消息循环和窗口是线程绑定的
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
@@ -51,7 +57,7 @@ func RunTray(quitChan chan struct{}, openConfigChan chan struct{}) {
 	defer close(quitChan)
 
 	trayQuitChan = quitChan
-	trayOpenConfigChan = openConfigChan
+	trayActionChan = actionChan
 
 	hInstance := win.GetModuleHandle(nil)
 	className := syscall.StringToUTF16Ptr("MouseFlowTrayClass")
@@ -87,6 +93,11 @@ func RunTray(quitChan chan struct{}, openConfigChan chan struct{}) {
 		return
 	}
 
+	// 注册全局热键，退出时注销
+	trayHwnd = hwnd
+	registerHotkeys(hwnd, cfg)
+	defer unregisterHotkeys(hwnd)
+
 	// 添加托盘图标
 	var nid win.NOTIFYICONDATA
 	nid.CbSize = uint32(unsafe.Sizeof(nid))
@@ -143,6 +154,14 @@ func RunTray(quitChan chan struct{}, openConfigChan chan struct{}) {
 	win.Shell_NotifyIcon(win.NIM_DELETE, &nid)
 }
 
+// sendTrayAction 请求游戏线程执行 a，队列已满时丢弃
+func sendTrayAction(a OverlayAction) {
+	select {
+	case trayActionChan <- a:
+	default:
+	}
+}
+
 // 窗口过程
 func wndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 	switch msg {
@@ -155,6 +174,26 @@ func wndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 
 			// 创建弹出菜单
 			hMenu := win.CreatePopupMenu()
+			pauseFlags := uint32(win.MF_STRING)
+			if overlayPaused.Load() {
+				pauseFlags |= win.MF_CHECKED
+			}
+			AppendMenu(hMenu, pauseFlags, IDM_PAUSE, syscall.StringToUTF16Ptr(T("MenuPause")))
+			spotlightFlags := uint32(win.MF_STRING)
+			if traySpotlight.Load() {
+				spotlightFlags |= win.MF_CHECKED
+			}
+			AppendMenu(hMenu, spotlightFlags, IDM_SPOTLIGHT, syscall.StringToUTF16Ptr(T("MenuSpotlight")))
+			laserFlags := uint32(win.MF_STRING)
+			if trayLaser.Load() {
+				laserFlags |= win.MF_CHECKED
+			}
+			AppendMenu(hMenu, laserFlags, IDM_LASER, syscall.StringToUTF16Ptr(T("MenuLaser")))
+			if trayInk.Load() {
+				AppendMenu(hMenu, win.MF_STRING, IDM_INK_UNDO, syscall.StringToUTF16Ptr(T("MenuInkUndo")))
+				AppendMenu(hMenu, win.MF_STRING, IDM_INK_CLEAR, syscall.StringToUTF16Ptr(T("MenuInkClear")))
+			}
+			AppendMenu(hMenu, win.MF_SEPARATOR, 0, nil)
 			AppendMenu(hMenu, win.MF_STRING, IDM_CONFIG, syscall.StringToUTF16Ptr(T("MenuConfig")))
 			AppendMenu(hMenu, win.MF_STRING, IDM_EXIT, syscall.StringToUTF16Ptr(T("MenuExit")))
 
@@ -172,11 +211,19 @@ func wndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 		id := win.LOWORD(uint32(wParam))
 		switch id {
 		case IDM_CONFIG:
-			// 通知主线程打开配置
-			select {
-			case trayOpenConfigChan <- struct{}{}:
-			default:
-			}
+			// 由游戏线程复制配置后打开设置窗口
+			sendTrayAction(ActionOpenConfig)
+		case IDM_SPOTLIGHT:
+			// 在游戏线程中切换
+			sendTrayAction(ActionToggleSpotlight)
+		case IDM_LASER:
+			sendTrayAction(ActionToggleLaser)
+		case IDM_PAUSE:
+			sendTrayAction(ActionPause)
+		case IDM_INK_UNDO:
+			sendTrayAction(ActionUndoInk)
+		case IDM_INK_CLEAR:
+			sendTrayAction(ActionClearInk)
 		case IDM_EXIT:
 			// 通知退出
 			win.PostQuitMessage(0)
@@ -184,6 +231,16 @@ func wndProc(hwnd win.HWND, msg uint32, wParam, lParam uintptr) uintptr {
 		}
 		return 0
 
+	case WM_HOTKEY:
+		if a, ok := hotkeyAction(wParam); ok {
+			sendTrayAction(a)
+		}
+		return 0
+
+	case WM_RELOAD_HOTKEYS:
+		reloadHotkeys(hwnd)
+		return 0
+
 	case win.WM_DESTROY:
 		win.PostQuitMessage(0)
 		return 0
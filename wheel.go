@@ -0,0 +1,122 @@
+package main
+
+import (
+	"math"
+)
+
+const (
+	// 同方向的滚动在该比例的持续时间内到来时合并到同一个指示器
+	wheelMergeLife = 0.5
+	// 最多显示的箭头数量
+	wheelMaxChevrons = 3
+)
+
+// WheelStyle 滚轮指示器样式
+type WheelStyle struct {
+	Enabled    bool     `json:"enabled"`
+	Color      [4]uint8 `json:"color"`       // RGBA，alpha 为 0 表示使用轨迹颜色
+	Size       float64  `json:"size"`        // 箭头大小 (像素)
+	Duration   float64  `json:"duration"`    // 持续时间 (秒)
+	MaxNotches float64  `json:"max_notches"` // 累计滚动达到该格数时强度最大
+}
+
+func defaultWheelStyle() WheelStyle {
+	return WheelStyle{
+		Enabled:    true,
+		Size:       10,
+		Duration:   0.5,
+		MaxNotches: 3,
+	}
+}
+
+// wheelMark 滚轮指示器
+type wheelMark struct {
+	X, Y       float64
+	DirX, DirY float64 // 屏幕坐标系中的滚动方向 (单位向量)
+	Amount     float64 // 累计滚动格数
+	Life       float64 // 1.0 -> 0.0
+	Offset     float64 // 沿滚动方向的滑动距离
+}
+
+// AddWheel 在光标处显示滚轮指示器，dx/dy 为滚动格数 (向右/向上为正)
+func (tm *TraceManager) AddWheel(x, y int, dx, dy float64) {
+	if !tm.config.Wheel.Enabled {
+		return
+	}
+	// 向上滚动时箭头朝上，屏幕 Y 轴向下
+	if dy != 0 {
+		tm.addWheelMark(x, y, 0, -math.Copysign(1, dy), math.Abs(dy))
+	}
+	if dx != 0 {
+		tm.addWheelMark(x, y, math.Copysign(1, dx), 0, math.Abs(dx))
+	}
+}
+
+func (tm *TraceManager) addWheelMark(x, y int, dirX, dirY, amount float64) {
+	// 连续滚动时合并到最近的同方向指示器，强度逐渐累积
+	for i := len(tm.wheels) - 1; i >= 0; i-- {
+		w := &tm.wheels[i]
+		if w.DirX == dirX && w.DirY == dirY && w.Life > wheelMergeLife {
+			w.X, w.Y = float64(x), float64(y)
+			w.Amount += amount
+			w.Life = 1
+			return
+		}
+	}
+	tm.wheels = append(tm.wheels, wheelMark{
+		X: float64(x), Y: float64(y),
+		DirX: dirX, DirY: dirY,
+		Amount: amount,
+		Life:   1,
+	})
+}
+
+// updateWheelMarks 推进滚轮指示器的动画，返回是否还有需要绘制的指示器
+func (tm *TraceManager) updateWheelMarks(dt float64) bool {
+	style := &tm.config.Wheel
+	decay := 1.0
+	if style.Duration > 0 {
+		decay = dt / style.Duration
+	}
+	active := 0
+	for i := range tm.wheels {
+		w := &tm.wheels[i]
+		w.Life -= decay
+		w.Offset += style.Size * 4 * dt // 箭头沿滚动方向滑出
+		if w.Life > 0 {
+			tm.wheels[active] = *w
+			active++
+		}
+	}
+	tm.wheels = tm.wheels[:active]
+	return active > 0
+}
+
+// buildWheelGeometry 生成滚轮指示器的三角形
+// 强度越大箭头越多、越大、越不透明
+func (tm *TraceManager) buildWheelGeometry() {
+	style := &tm.config.Wheel
+	for _, w := range tm.wheels {
+		intensity := 1.0
+		if style.MaxNotches > 0 {
+			intensity = math.Min(1, w.Amount/style.MaxNotches)
+		}
+		c := tm.styleColor(style.Color, w.Life*(0.4+0.6*intensity))
+
+		size := style.Size * (0.7 + 0.5*intensity)
+		thickness := math.Max(1.5, size/4)
+		count := max(1, min(wheelMaxChevrons, int(math.Ceil(intensity*wheelMaxChevrons))))
+
+		// 箭头从光标外侧开始，沿滚动方向依次排列
+		for i := 0; i < count; i++ {
+			d := size*1.5 + w.Offset + float64(i)*size*0.8
+			tipX := w.X + w.DirX*d
+			tipY := w.Y + w.DirY*d
+			// 两翼向后方展开
+			backX, backY := tipX-w.DirX*size*0.6, tipY-w.DirY*size*0.6
+			sideX, sideY := -w.DirY*size, w.DirX*size
+			tm.addSegment(backX+sideX, backY+sideY, tipX, tipY, thickness, c)
+			tm.addSegment(backX-sideX, backY-sideY, tipX, tipY, thickness, c)
+		}
+	}
+}